@@ -1,12 +1,17 @@
 package sdk
 
 import (
+	"context"
+	"fmt"
 	"os"
+	"runtime/debug"
 	"sync"
+	"time"
 
 	"github.com/XunleiBlockchain/tc-libs/accounts"
 	"github.com/XunleiBlockchain/tc-libs/accounts/keystore"
 	"github.com/XunleiBlockchain/tc-libs/common"
+	"github.com/XunleiBlockchain/tc-libs/crypto"
 )
 
 func makeAccountManager(keydir string) (*accounts.Manager, error) {
@@ -21,6 +26,142 @@ func makeAccountManager(keydir string) (*accounts.Manager, error) {
 	return accounts.NewManager(backends...), nil
 }
 
+func (sdk *SDKImpl) keyStore() *keystore.KeyStore {
+	return sdk.am.Backends(keystore.KeyStoreType)[0].(*keystore.KeyStore)
+}
+
+// setAccountCryptoType selects the key type used for newly created accounts.
+// Keys already in the keystore are loaded according to their own type.
+func setAccountCryptoType(t string) error {
+	switch t {
+	case "":
+		crypto.SetLocalAccountType(crypto.CryptoTypeSecp256K1)
+	case crypto.CryptoTypeSecp256K1, crypto.CryptoTypeGM:
+		crypto.SetLocalAccountType(t)
+	default:
+		return fmt.Errorf("invalid crypto type: %s", t)
+	}
+	return nil
+}
+
+// cryptoConfig is the account key type of the SDKs not yet closed. tc-libs keeps
+// it process wide, so the SDKs of a process must agree on it.
+var cryptoConfig struct {
+	mu         sync.Mutex
+	cryptoType string
+	users      int // 未关闭的SDK数
+}
+
+// acquireCrypto sets the key type of a new SDK, failing if it differs from that
+// of the SDKs not yet closed. Close calls releaseCrypto.
+func acquireCrypto(cryptoType string) error {
+	if cryptoType == "" {
+		cryptoType = crypto.CryptoTypeSecp256K1
+	}
+	cryptoConfig.mu.Lock()
+	defer cryptoConfig.mu.Unlock()
+	if cryptoConfig.users > 0 {
+		if cryptoType != cryptoConfig.cryptoType {
+			return fmt.Errorf("crypto type %s conflicts with %s of a running SDK", cryptoType, cryptoConfig.cryptoType)
+		}
+		cryptoConfig.users++
+		return nil
+	}
+	if err := setAccountCryptoType(cryptoType); err != nil {
+		return err
+	}
+	cryptoConfig.cryptoType = cryptoType
+	cryptoConfig.users = 1
+	return nil
+}
+
+func releaseCrypto() {
+	cryptoConfig.mu.Lock()
+	defer cryptoConfig.mu.Unlock()
+	cryptoConfig.users--
+}
+
+// maxUnlockDuration caps the timed unlock of UnlockAccount
+const maxUnlockDuration = 365 * 24 * time.Hour
+
+// AccountState describes whether an account is unlocked and when it relocks
+type AccountState struct {
+	Address  common.Address `json:"address"`
+	Unlocked bool           `json:"unlocked"`
+	Expires  int64          `json:"expires"` // 自动锁定的unix时间 0表示未解锁或永久解锁
+}
+
+// unlockTracker records when unlocked accounts relock, the keystore only
+// keeps the expire timer internally.
+type unlockTracker struct {
+	mu      sync.Mutex
+	expires map[common.Address]time.Time // zero time means unlocked indefinitely
+}
+
+// unlock unlocks acc for timeout, 0 means indefinitely
+func (t *unlockTracker) unlock(ks *keystore.KeyStore, acc accounts.Account, passwd string, timeout time.Duration) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.expires == nil {
+		t.expires = make(map[common.Address]time.Time)
+	}
+	if exp, ok := t.expires[acc.Address]; ok && exp.IsZero() && timeout > 0 {
+		// keystore ignores timeouts for indefinitely unlocked accounts,
+		// so check the password first and then relock it
+		err := ks.TimedUnlock(acc, passwd, timeout)
+		debug.FreeOSMemory()
+		if err != nil {
+			return err
+		}
+		ks.Lock(acc.Address)
+	}
+	err := ks.TimedUnlock(acc, passwd, timeout)
+	debug.FreeOSMemory()
+	if err != nil {
+		return err
+	}
+	if timeout > 0 {
+		t.expires[acc.Address] = time.Now().Add(timeout)
+	} else {
+		t.expires[acc.Address] = time.Time{}
+	}
+	return nil
+}
+
+func (t *unlockTracker) lock(ks *keystore.KeyStore, addr common.Address) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ks.Lock(addr)
+	delete(t.expires, addr)
+}
+
+// lockAll locks every account unlocked through t
+func (t *unlockTracker) lockAll(ks *keystore.KeyStore) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for addr := range t.expires {
+		ks.Lock(addr)
+		delete(t.expires, addr)
+	}
+}
+
+func (t *unlockTracker) state(ks *keystore.KeyStore, addr common.Address) *AccountState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	st := &AccountState{Address: addr}
+	// SignHash is the only way to ask keystore whether the key is in memory
+	hash := common.BytesToHash([]byte{1})
+	if _, err := ks.SignHash(accounts.Account{Address: addr}, hash[:]); err == keystore.ErrLocked {
+		delete(t.expires, addr)
+		return st
+	}
+	st.Unlocked = true
+	if exp := t.expires[addr]; !exp.IsZero() {
+		st.Expires = exp.Unix()
+	}
+	return st
+}
+
 type addrLocker struct {
 	mu    sync.Mutex
 	locks map[common.Address]*sync.Mutex
@@ -45,3 +186,27 @@ func (l *addrLocker) lockAddr(address common.Address) {
 func (l *addrLocker) unlockAddr(address common.Address) {
 	l.lock(address).Unlock()
 }
+
+// signTx signs stx with the unlocked account and reports it to the metrics hook
+func (sdk *SDKImpl) signTx(ctx context.Context, wallet accounts.Wallet, account accounts.Account, stx accounts.SingerTx) (accounts.SingerTx, error) {
+	_, span := startSpan(sdk.tracer, ctx, "sign")
+	span.SetAttributes("account", account.Address.Hex(), "passphrase", false)
+	start := time.Now()
+	signed, err := wallet.SignTx(account, stx, sdk.signParam)
+	debug.FreeOSMemory()
+	sdk.metrics.ObserveSign(&SignMetric{Account: account.Address, Duration: time.Since(start), Err: err})
+	span.end(err)
+	return signed, err
+}
+
+// signTxWithPassphrase signs stx with passwd and reports it to the metrics hook
+func (sdk *SDKImpl) signTxWithPassphrase(ctx context.Context, wallet accounts.Wallet, account accounts.Account, passwd string, stx accounts.SingerTx) (accounts.SingerTx, error) {
+	_, span := startSpan(sdk.tracer, ctx, "sign")
+	span.SetAttributes("account", account.Address.Hex(), "passphrase", true)
+	start := time.Now()
+	signed, err := wallet.SignTxWithPassphrase(account, passwd, stx, sdk.signParam)
+	debug.FreeOSMemory()
+	sdk.metrics.ObserveSign(&SignMetric{Account: account.Address, Passphrase: true, Duration: time.Since(start), Err: err})
+	span.end(err)
+	return signed, err
+}
@@ -1,9 +1,9 @@
 package sdk
 
 import (
+	"context"
 	"errors"
 	"math/big"
-	"strconv"
 
 	"github.com/XunleiBlockchain/baas-sdk-go/types"
 	"github.com/XunleiBlockchain/tc-libs/common"
@@ -19,51 +19,56 @@ type SendTxArgs struct {
 	Nonce    *uint64         `json:"nonce"`
 }
 
-func (args *SendTxArgs) parseFromArgs(params interface{}) (err error) {
-	txArgs := params.(map[string]interface{})
-	var succ bool
-	if from, ok := txArgs["from"]; ok {
-		args.From = common.HexToAddress(from.(string))
-	}
-	if to, ok := txArgs["to"]; ok {
-		if !common.IsHexAddress(to.(string)) {
-			return errors.New("invalid to address")
-		}
-		toAddr := common.HexToAddress(to.(string))
-		args.To = &toAddr
+func (args *SendTxArgs) parseFromArgs(x interface{}, field string) (err error) {
+	txArgs, err := toObject(x, field)
+	if err != nil {
+		return err
+	}
+	if txArgs["from"] == nil {
+		return newParamError(field+".from", "missing")
+	}
+	if args.From, err = toAddress(txArgs["from"], field+".from"); err != nil {
+		return err
+	}
+	if txArgs["to"] == nil {
+		return newParamError(field+".to", "missing")
 	}
-	if gas, ok := txArgs["gas"]; ok {
-		args.Gas, succ = new(big.Int).SetString(gas.(string), 0)
-		if !succ {
-			return errors.New("gas err.")
+	toAddr, err := toAddress(txArgs["to"], field+".to")
+	if err != nil {
+		return err
+	}
+	args.To = &toAddr
+	if gas := txArgs["gas"]; gas != nil {
+		if args.Gas, err = toBig(gas, field+".gas"); err != nil {
+			return err
 		}
 	}
-	if gasPrice, ok := txArgs["gasPrice"]; ok {
-		args.GasPrice, succ = new(big.Int).SetString(gasPrice.(string), 0)
-		if !succ {
-			return errors.New("gasPrice err.")
+	if gasPrice := txArgs["gasPrice"]; gasPrice != nil {
+		if args.GasPrice, err = toBig(gasPrice, field+".gasPrice"); err != nil {
+			return err
 		}
 	}
-	if value, ok := txArgs["value"]; ok {
-		args.Value, succ = new(big.Int).SetString(value.(string), 0)
-		if !succ {
-			return errors.New("value err.")
+	if value := txArgs["value"]; value != nil {
+		if args.Value, err = toBig(value, field+".value"); err != nil {
+			return err
 		}
 	}
-	if data, ok := txArgs["data"]; ok {
-		args.Data = common.FromHex(data.(string))
+	if data := txArgs["data"]; data != nil {
+		if args.Data, err = toHexBytes(data, field+".data"); err != nil {
+			return err
+		}
 	}
-	if nonce, ok := txArgs["nonce"]; ok {
-		nonceUint64, err := strconv.ParseUint(nonce.(string), 0, 64)
+	if nonce := txArgs["nonce"]; nonce != nil {
+		n, err := toUint(nonce, field+".nonce", 64)
 		if err != nil {
-			return errors.New("nonce err.")
+			return err
 		}
-		args.Nonce = &nonceUint64
+		args.Nonce = &n
 	}
 	return nil
 }
 
-func (args *SendTxArgs) setDefaults(c *client) error {
+func (args *SendTxArgs) setDefaults(ctx context.Context, c *client) error {
 	if args.GasPrice == nil {
 		args.GasPrice = big.NewInt(1e11)
 	}
@@ -71,14 +76,14 @@ func (args *SendTxArgs) setDefaults(c *client) error {
 		args.Value = new(big.Int)
 	}
 	if args.Gas == nil {
-		gas, xerr := c.estimateGas(args.From.String(), args.To.String(), common.ToHex(args.Data), *args.Value)
+		gas, xerr := c.estimateGas(ctx, args.From.String(), args.To.String(), common.ToHex(args.Data), *args.Value)
 		if xerr != nil && xerr.Code != 0 {
 			return errors.New(xerr.Msg)
 		}
 		args.Gas = gas
 	}
 	if args.Nonce == nil {
-		nonce, xerr := c.getNonce(args.From.String())
+		nonce, xerr := c.getNonce(ctx, args.From.String())
 		if xerr != nil && xerr.Code != 0 {
 			return errors.New(xerr.Msg)
 		}
@@ -92,13 +97,18 @@ func (args *SendTxArgs) setData(data []byte) error {
 	return nil
 }
 
-func (args *SendTxArgs) toTransaction() types.Tx {
+// toTransaction returns the transaction of args signed with the sign hash type signHash
+func (args *SendTxArgs) toTransaction(signHash string) types.Tx {
 	// nonce toAddress amout gasLimit gasPrice data
-	return types.NewTransaction(uint64(*args.Nonce), *args.To, args.Value, args.Gas.Uint64(), args.GasPrice, args.Data)
+	tx := types.NewTransaction(uint64(*args.Nonce), *args.To, args.Value, args.Gas.Uint64(), args.GasPrice, args.Data)
+	tx.SetSignHashType(signHash)
+	return tx
 }
 
-func (args *SendTxArgs) toContractTransaction() types.Tx {
-	return types.NewTransaction(uint64(*args.Nonce), *args.To, args.Value, args.Gas.Uint64(), args.GasPrice, args.Data)
+func (args *SendTxArgs) toContractTransaction(signHash string) types.Tx {
+	tx := types.NewTransaction(uint64(*args.Nonce), *args.To, args.Value, args.Gas.Uint64(), args.GasPrice, args.Data)
+	tx.SetSignHashType(signHash)
+	return tx
 }
 
 type ContractExtension struct {
@@ -111,15 +121,32 @@ type ContractExtension struct {
 	Desc      string `json:"desc"`
 }
 
-func (args *ContractExtension) parseFromArgs(params interface{}) (err error) {
-	extArgs := params.(map[string]interface{})
-	args.Callback = checkStrRes(extArgs["callback"], "")
-	args.PrepayID = checkStrRes(extArgs["prepay_id"], "")
-	args.ServiceID = checkStrRes(extArgs["service_id"], "")
-	args.TxType = checkStrRes(extArgs["tx_type"], "contract")
-	args.Sign = checkStrRes(extArgs["sign"], "")
-	args.Title = checkStrRes(extArgs["title"], "")
-	args.Desc = checkStrRes(extArgs["desc"], "")
+func (args *ContractExtension) parseFromArgs(x interface{}, field string) (err error) {
+	extArgs, err := toObject(x, field)
+	if err != nil {
+		return err
+	}
+	fields := []struct {
+		key string
+		dst *string
+		def string
+	}{
+		{"callback", &args.Callback, ""},
+		{"prepay_id", &args.PrepayID, ""},
+		{"service_id", &args.ServiceID, ""},
+		{"tx_type", &args.TxType, "contract"},
+		{"sign", &args.Sign, ""},
+		{"title", &args.Title, ""},
+		{"desc", &args.Desc, ""},
+	}
+	for _, f := range fields {
+		*f.dst = f.def
+		if v := extArgs[f.key]; v != nil {
+			if *f.dst, err = toString(v, field+"."+f.key); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
@@ -129,10 +156,27 @@ type CallArgs struct {
 	Data string `json:"data"`
 }
 
-func (args *CallArgs) parseFromArgs(params interface{}) (err error) {
-	txArgs := params.(map[string]interface{})
-	args.From = checkStrRes(txArgs["from"], "")
-	args.To = checkStrRes(txArgs["to"], "")
-	args.Data = checkStrRes(txArgs["data"], "")
+// parseFromArgs validates from, to and data and keeps them as given
+func (args *CallArgs) parseFromArgs(x interface{}, field string) (err error) {
+	txArgs, err := toObject(x, field)
+	if err != nil {
+		return err
+	}
+	for _, key := range []string{"from", "to"} {
+		if txArgs[key] == nil {
+			return newParamError(field+"."+key, "missing")
+		}
+		if _, err = toAddress(txArgs[key], field+"."+key); err != nil {
+			return err
+		}
+	}
+	args.From = txArgs["from"].(string)
+	args.To = txArgs["to"].(string)
+	if data := txArgs["data"]; data != nil {
+		if _, err = toHexBytes(data, field+".data"); err != nil {
+			return err
+		}
+		args.Data = data.(string)
+	}
 	return nil
 }
@@ -0,0 +1,245 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Auth schemes, see Config.AuthScheme
+const (
+	AuthSchemeV1 = "v1" // MD5(hex(SHA256(rand&params&chainid&sdkid&key))) 仅签名字符串/布尔参数(默认 兼容旧版接入层)
+	AuthSchemeV2 = "v2" // HMAC-SHA256(key, 规范化JSON{method,params,timestamp,nonce,chainid,sdkid})
+)
+
+// DefaultAuthMaxSkew is the default allowed clock skew of v2 auth timestamps
+const DefaultAuthMaxSkew = 5 * time.Minute
+
+type rpcAuth struct {
+	Version   string `json:"version,omitempty"`
+	ChainID   string `json:"chainid"`
+	ID        string `json:"sdkid"`
+	Rand      string `json:"rand"`
+	Timestamp int64  `json:"timestamp,omitempty"`
+	Sign      string `json:"sign"`
+}
+
+// genAuth signs req with the current credentials and the configured auth scheme,
+// nil if the credentials are incomplete
+func (c *client) genAuth(ctx context.Context, req *rpcRequest) (*rpcAuth, error) {
+	creds, err := c.creds.Credentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	authInfo := creds.Active(now)
+	if c.authScheme == AuthSchemeV2 {
+		return genRpcAuthV2(c.log, req.method, req.params, authInfo, now)
+	}
+	authParams := req.authParams
+	if authParams == nil {
+		authParams, _ = req.params.([]interface{})
+	}
+	return genRpcAuth(c.log, authParams, authInfo), nil
+}
+
+// ------------------------------- v1 -------------------------------
+func genRpcAuth(log Logger, params []interface{}, authInfo AuthInfo) *rpcAuth {
+	if authInfo.ChainID == "" || authInfo.ID == "" || authInfo.Key == "" {
+		return nil
+	}
+	rand := getRandString(16)
+	md5sum := signRpcAuthV1(rand, params, authInfo)
+	log.Debug("rpc-auth", "rand", rand, "sign", md5sum)
+	return &rpcAuth{
+		ChainID: authInfo.ChainID,
+		ID:      authInfo.ID,
+		Rand:    rand,
+		Sign:    md5sum,
+	}
+}
+
+func signRpcAuthV1(rand string, params []interface{}, authInfo AuthInfo) string {
+	str := rand
+	for _, v := range params {
+		switch v.(type) {
+		case string:
+			str = str + strAND(v.(string))
+		case bool:
+			str = str + strAND(strconv.FormatBool(v.(bool)))
+		}
+	}
+	str = str + strAND(authInfo.ChainID) + strAND(authInfo.ID) + strAND(authInfo.Key)
+	hash := sha256.Sum256([]byte(str))
+	return fmt.Sprintf("%x", md5.Sum([]byte(fmt.Sprintf("%x", hash[:]))))
+}
+
+// v1AuthParams returns the params signed by v1 for method, estimateGas and call
+// sign from, to and data of their call object.
+func v1AuthParams(method string, params []interface{}) []interface{} {
+	if !strings.HasSuffix(method, "_estimateGas") && !strings.HasSuffix(method, "_call") {
+		return params
+	}
+	if len(params) == 0 {
+		return nil
+	}
+	obj, _ := params[0].(map[string]interface{})
+	return []interface{}{obj["from"], obj["to"], obj["data"]}
+}
+
+// ------------------------------- v2 -------------------------------
+func genRpcAuthV2(log Logger, method string, params interface{}, authInfo AuthInfo, now time.Time) (*rpcAuth, error) {
+	if authInfo.ChainID == "" || authInfo.ID == "" || authInfo.Key == "" {
+		return nil, nil
+	}
+	auth := &rpcAuth{
+		Version:   AuthSchemeV2,
+		ChainID:   authInfo.ChainID,
+		ID:        authInfo.ID,
+		Rand:      getRandHex(16),
+		Timestamp: now.Unix(),
+	}
+	sign, err := signRpcAuthV2(method, params, auth, authInfo.Key)
+	if err != nil {
+		return nil, err
+	}
+	auth.Sign = sign
+	log.Debug("rpc-auth", "version", auth.Version, "rand", auth.Rand, "timestamp", auth.Timestamp, "sign", auth.Sign)
+	return auth, nil
+}
+
+func signRpcAuthV2(method string, params interface{}, auth *rpcAuth, key string) (string, error) {
+	msg, err := canonicalJSON(map[string]interface{}{
+		"method":    method,
+		"params":    params,
+		"timestamp": auth.Timestamp,
+		"nonce":     auth.Rand,
+		"chainid":   auth.ChainID,
+		"sdkid":     auth.ID,
+	})
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(msg)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// canonicalJSON encodes v with sorted object keys, no insignificant whitespace and
+// numbers kept as written, so that both sides sign the same bytes.
+func canonicalJSON(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var generic interface{}
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return json.Marshal(generic)
+}
+
+// ------------------------------- verifier -------------------------------
+// AuthVerifier verifies the auth field of BaaS JSON-RPC requests of both schemes,
+// e.g. in a mock BaaS server.
+type AuthVerifier struct {
+	// Keys returns the accepted keys of the sdk id, more than one during key rotation
+	Keys    func(chainID, id string) []string
+	MaxSkew time.Duration // v2 时间戳允许的偏差 默认 DefaultAuthMaxSkew
+
+	mu     sync.Mutex
+	nonces map[string]time.Time
+}
+
+// Verify checks the auth of a JSON-RPC request body. v2 requests are also checked
+// for timestamp skew and nonce replay.
+func (v *AuthVerifier) Verify(body []byte) error {
+	var req struct {
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+		Auth   *rpcAuth        `json:"auth"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return err
+	}
+	if req.Auth == nil {
+		return errors.New("auth: missing")
+	}
+	auth := req.Auth
+	keys := v.Keys(auth.ChainID, auth.ID)
+	if len(keys) == 0 {
+		return fmt.Errorf("auth: unknown sdkid %s of chain %s", auth.ID, auth.ChainID)
+	}
+	dec := json.NewDecoder(bytes.NewReader(req.Params))
+	dec.UseNumber()
+	var params interface{}
+	if len(req.Params) != 0 {
+		if err := dec.Decode(&params); err != nil {
+			return err
+		}
+	}
+	switch auth.Version {
+	case "", AuthSchemeV1:
+		list, _ := params.([]interface{})
+		for _, key := range keys {
+			sign := signRpcAuthV1(auth.Rand, v1AuthParams(req.Method, list), AuthInfo{ChainID: auth.ChainID, ID: auth.ID, Key: key})
+			if hmac.Equal([]byte(sign), []byte(auth.Sign)) {
+				return nil
+			}
+		}
+		return errors.New("auth: invalid sign")
+	case AuthSchemeV2:
+		maxSkew := v.MaxSkew
+		if maxSkew == 0 {
+			maxSkew = DefaultAuthMaxSkew
+		}
+		now := time.Now()
+		ts := time.Unix(auth.Timestamp, 0)
+		if ts.Before(now.Add(-maxSkew)) || ts.After(now.Add(maxSkew)) {
+			return fmt.Errorf("auth: timestamp %d out of range", auth.Timestamp)
+		}
+		for _, key := range keys {
+			sign, err := signRpcAuthV2(req.Method, params, auth, key)
+			if err != nil {
+				return err
+			}
+			if hmac.Equal([]byte(sign), []byte(auth.Sign)) {
+				return v.checkNonce(auth.ID+"/"+auth.Rand, now, maxSkew)
+			}
+		}
+		return errors.New("auth: invalid sign")
+	default:
+		return fmt.Errorf("auth: unsupported version %s", auth.Version)
+	}
+}
+
+// checkNonce rejects a nonce seen within the skew window
+func (v *AuthVerifier) checkNonce(nonce string, now time.Time, maxSkew time.Duration) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.nonces == nil {
+		v.nonces = make(map[string]time.Time)
+	}
+	for n, t := range v.nonces {
+		if now.Sub(t) > 2*maxSkew {
+			delete(v.nonces, n)
+		}
+	}
+	if _, ok := v.nonces[nonce]; ok {
+		return errors.New("auth: nonce replayed")
+	}
+	v.nonces[nonce] = now
+	return nil
+}
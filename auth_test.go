@@ -0,0 +1,127 @@
+package sdk
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testAuthInfo = AuthInfo{ChainID: "30261", ID: "sdk-1", Key: "secret-key"}
+
+func authBody(t *testing.T, method string, params interface{}, auth *rpcAuth) []byte {
+	body, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": method, "params": params, "auth": auth})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+func testVerifier(keys ...string) *AuthVerifier {
+	return &AuthVerifier{Keys: func(chainID, id string) []string {
+		if chainID != testAuthInfo.ChainID || id != testAuthInfo.ID {
+			return nil
+		}
+		return keys
+	}}
+}
+
+func checkAuthErr(t *testing.T, name string, err error, want string) {
+	t.Helper()
+	switch {
+	case want == "" && err != nil:
+		t.Errorf("%s: %v", name, err)
+	case want != "" && (err == nil || !strings.Contains(err.Error(), want)):
+		t.Errorf("%s: err = %v, want %q", name, err, want)
+	}
+}
+
+func TestAuthV1(t *testing.T) {
+	const method = "tcapi_getBalance"
+	params := []interface{}{"0x622bc0938fae8b028fcf124f9ba8580719009fdc", "latest", true}
+	auth := genRpcAuth(NopLogger(), params, testAuthInfo)
+	body := authBody(t, method, params, auth)
+
+	checkAuthErr(t, "valid", testVerifier(testAuthInfo.Key).Verify(body), "")
+	// v1 has no nonce, the same request verifies again
+	checkAuthErr(t, "resent", testVerifier(testAuthInfo.Key).Verify(body), "")
+	checkAuthErr(t, "rotated key", testVerifier("old-key", testAuthInfo.Key).Verify(body), "")
+	checkAuthErr(t, "wrong key", testVerifier("other-key").Verify(body), "invalid sign")
+	checkAuthErr(t, "unknown sdkid", testVerifier().Verify(body), "unknown sdkid")
+
+	tampered := authBody(t, method, []interface{}{"0x33d4fcb75ce608920c7e5755304c282141dfc4dc", "latest", true}, auth)
+	checkAuthErr(t, "tampered", testVerifier(testAuthInfo.Key).Verify(tampered), "invalid sign")
+
+	checkAuthErr(t, "missing", testVerifier(testAuthInfo.Key).Verify(authBody(t, method, params, nil)), "missing")
+}
+
+// v1 signs only from, to and data of the call object of estimateGas and call
+func TestAuthV1CallObject(t *testing.T) {
+	const method = "tcapi_estimateGas"
+	call := map[string]interface{}{"from": "0x622bc0938fae8b028fcf124f9ba8580719009fdc", "to": "0x33d4fcb75ce608920c7e5755304c282141dfc4dc", "data": "0x01", "value": "0x10"}
+	params := []interface{}{call}
+	auth := genRpcAuth(NopLogger(), v1AuthParams(method, params), testAuthInfo)
+	checkAuthErr(t, "valid", testVerifier(testAuthInfo.Key).Verify(authBody(t, method, params, auth)), "")
+
+	call["data"] = "0x02"
+	checkAuthErr(t, "tampered data", testVerifier(testAuthInfo.Key).Verify(authBody(t, method, params, auth)), "invalid sign")
+}
+
+func TestAuthV2(t *testing.T) {
+	const method = "tcapi_sendRawTransaction"
+	// nested objects and numbers as written exercise the canonical JSON
+	params := []interface{}{"0xf86b", map[string]interface{}{"b": json.Number("1.50"), "a": []interface{}{json.Number("10"), "x"}}}
+	auth, err := genRpcAuthV2(NopLogger(), method, params, testAuthInfo, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := authBody(t, method, params, auth)
+
+	v := testVerifier(testAuthInfo.Key)
+	checkAuthErr(t, "valid", v.Verify(body), "")
+	checkAuthErr(t, "replayed", v.Verify(body), "nonce replayed")
+	checkAuthErr(t, "other verifier", testVerifier("old-key", testAuthInfo.Key).Verify(body), "")
+	checkAuthErr(t, "wrong key", testVerifier("other-key").Verify(body), "invalid sign")
+
+	tampered := authBody(t, "tcapi_sendTransaction", params, auth)
+	checkAuthErr(t, "tampered method", testVerifier(testAuthInfo.Key).Verify(tampered), "invalid sign")
+
+	forged := *auth
+	forged.Timestamp++
+	checkAuthErr(t, "tampered timestamp", testVerifier(testAuthInfo.Key).Verify(authBody(t, method, params, &forged)), "invalid sign")
+}
+
+func TestAuthV2Skew(t *testing.T) {
+	const method = "tcapi_blockNumber"
+	for _, c := range []struct {
+		name    string
+		offset  time.Duration
+		maxSkew time.Duration
+		want    string
+	}{
+		{"now", 0, 0, ""},
+		{"within default", -4 * time.Minute, 0, ""},
+		{"past default", -6 * time.Minute, 0, "out of range"},
+		{"future default", 6 * time.Minute, 0, "out of range"},
+		{"within custom", -20 * time.Second, 30 * time.Second, ""},
+		{"past custom", -40 * time.Second, 30 * time.Second, "out of range"},
+	} {
+		auth, err := genRpcAuthV2(NopLogger(), method, []interface{}{}, testAuthInfo, time.Now().Add(c.offset))
+		if err != nil {
+			t.Fatal(err)
+		}
+		v := testVerifier(testAuthInfo.Key)
+		v.MaxSkew = c.maxSkew
+		checkAuthErr(t, c.name, v.Verify(authBody(t, method, []interface{}{}, auth)), c.want)
+	}
+}
+
+func TestCanonicalJSON(t *testing.T) {
+	got, err := canonicalJSON(map[string]interface{}{"b": json.Number("1.50"), "a": map[string]interface{}{"d": 1, "c": "x"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"a":{"c":"x","d":1},"b":1.50}`; string(got) != want {
+		t.Errorf("canonicalJSON = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,626 @@
+// Package baastest provides fakes of the BaaS access layer for testing.
+//
+// Backend is a simulated chain serving the tcapi_* JSON-RPC methods used by the SDK
+// client fully in memory: it verifies the auth field of every request, decodes and
+// validates submitted BAL transactions, applies value transfers, tracks nonces and
+// receipts, mines blocks on demand and can inject failures. Plugged in as
+// Config.Transport it needs no network; Server serves it over a local HTTP port.
+package baastest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/XunleiBlockchain/baas-sdk-go"
+	"github.com/XunleiBlockchain/baas-sdk-go/types"
+	"github.com/XunleiBlockchain/tc-libs/bal"
+	"github.com/XunleiBlockchain/tc-libs/common"
+	"github.com/XunleiBlockchain/tc-libs/common/hexutil"
+	"github.com/XunleiBlockchain/tc-libs/crypto"
+)
+
+// JSON-RPC error codes returned by the fake
+const (
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeAuth           = -32001 // auth校验失败
+	CodeTxRejected     = -32000 // 交易校验失败 如nonce、余额、签名
+)
+
+// Options configures a Server, zero fields take the defaults
+type Options struct {
+	ChainID   int64        // 链ID 默认30261
+	Namespace string       // 名称空间 默认tcapi
+	AuthInfo  sdk.AuthInfo // 接受的通信凭证 默认 {ChainID, "baastest", "baastest-key"}
+	GasPrice  *big.Int     // 默认1e11
+	SignHash  string       // 交易签名哈希算法 keccak256(默认) 或 sm3
+	Logger    sdk.Logger
+}
+
+// CallHandler answers tcapi_call, result is the hex encoded return data
+type CallHandler func(from, to, data string) (result string, err error)
+
+// Backend is a simulated chain implementing the BaaS RPC surface in memory
+type Backend struct {
+	chainID   int64
+	namespace string
+	auth      sdk.AuthInfo
+	verifier  *sdk.AuthVerifier
+	signer    *big.Int
+	signHash  string
+	log       sdk.Logger
+
+	mu       sync.Mutex
+	gasPrice *big.Int
+	balances map[common.Address]*big.Int
+	nonces   map[common.Address]uint64
+	txs      map[common.Hash]*txRecord
+	blocks   []*block
+	pending  []*txRecord
+	autoMine bool
+	call     CallHandler
+
+	faultMu sync.Mutex // faults are taken with or without mu held
+	faults  map[string][]Fault
+}
+
+type txRecord struct {
+	tx      *types.Transaction
+	from    common.Address
+	block   *block // 未打包为nil
+	index   int
+	gasUsed uint64
+	failed  bool
+}
+
+type block struct {
+	number uint64
+	hash   common.Hash
+	parent common.Hash
+	time   int64
+	txs    []*txRecord
+}
+
+// NewBackend creates a simulated chain with the genesis block, transactions stay
+// pending until Commit unless SetAutoMine is on.
+func NewBackend(opts *Options) *Backend {
+	if opts == nil {
+		opts = &Options{}
+	}
+	s := &Backend{
+		chainID:   opts.ChainID,
+		namespace: opts.Namespace,
+		auth:      opts.AuthInfo,
+		gasPrice:  opts.GasPrice,
+		signHash:  opts.SignHash,
+		log:       opts.Logger,
+		balances:  make(map[common.Address]*big.Int),
+		nonces:    make(map[common.Address]uint64),
+		txs:       make(map[common.Hash]*txRecord),
+		faults:    make(map[string][]Fault),
+	}
+	if s.chainID == 0 {
+		s.chainID = 30261
+	}
+	if s.namespace == "" {
+		s.namespace = "tcapi"
+	}
+	if s.auth.ID == "" {
+		s.auth = sdk.AuthInfo{ChainID: strconv.FormatInt(s.chainID, 10), ID: "baastest", Key: "baastest-key"}
+	}
+	if s.gasPrice == nil {
+		s.gasPrice = big.NewInt(1e11)
+	}
+	if s.log == nil {
+		s.log = sdk.NopLogger()
+	}
+	s.signer = sdk.ChainSignParam(s.chainID)
+	s.verifier = &sdk.AuthVerifier{
+		Keys: func(chainID, id string) []string {
+			if chainID != s.auth.ChainID {
+				return nil
+			}
+			return s.auth.AcceptedKeys(id, time.Now())
+		},
+	}
+	s.blocks = []*block{s.newBlock(nil)}
+	return s
+}
+
+// Config returns a sdk.Config using the backend as its transport with keystore as the key directory
+func (s *Backend) Config(keystore string) *sdk.Config {
+	return &sdk.Config{
+		Keystore:       keystore,
+		UnlockAccounts: make(map[string]string),
+		XHost:          "baastest",
+		Namespace:      s.namespace,
+		ChainID:        s.chainID,
+		AuthInfo:       s.auth,
+		SignHash:       s.signHash,
+		Transport:      s,
+	}
+}
+
+// SetAutoMine sets whether every accepted transaction is mined into a new block at once
+func (s *Backend) SetAutoMine(on bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.autoMine = on
+	if on && len(s.pending) != 0 {
+		s.commit()
+	}
+}
+
+// Commit mines the pending transactions into a new block and returns its number
+func (s *Backend) Commit() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.commit()
+}
+
+// Pending returns the number of transactions waiting for Commit
+func (s *Backend) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.pending)
+}
+
+// SetBalance sets the balance of addr
+func (s *Backend) SetBalance(addr common.Address, balance *big.Int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.balances[addr] = new(big.Int).Set(balance)
+}
+
+// Balance returns the balance of addr
+func (s *Backend) Balance(addr common.Address) *big.Int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.balance(addr)
+}
+
+// Nonce returns the next nonce of addr
+func (s *Backend) Nonce(addr common.Address) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.nonces[addr]
+}
+
+// SetGasPrice sets the price returned by tcapi_gasPrice
+func (s *Backend) SetGasPrice(price *big.Int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gasPrice = new(big.Int).Set(price)
+}
+
+// HandleCall sets the handler of tcapi_call, which returns "0x" by default
+func (s *Backend) HandleCall(h CallHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.call = h
+}
+
+// Transaction returns the accepted transaction of hash and its sender
+func (s *Backend) Transaction(hash common.Hash) (*types.Transaction, common.Address, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.txs[hash]
+	if !ok {
+		return nil, common.Address{}, false
+	}
+	return rec.tx, rec.from, true
+}
+
+// ------------------------------- http -------------------------------
+type rpcRequest struct {
+	ID      interface{}     `json:"id"`
+	Jsonrpc string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+type rpcError struct {
+	Code int    `json:"code"`
+	Msg  string `json:"message"`
+}
+
+// RoundTrip implements sdk.RoundTripper, so the backend can be set as Config.Transport
+func (s *Backend) RoundTrip(req *sdk.Request) (*sdk.Response, error) {
+	api := req.API
+	if api == "" {
+		api = strings.TrimPrefix(req.Method, s.namespace+"_")
+	}
+	if _, ok := s.takeFault(api, FaultTimeout); ok {
+		return &sdk.Response{}, &timeoutError{api: api}
+	}
+	body := s.serve(api, req.Body)
+	if _, ok := s.takeFault(api, FaultLostReply); ok {
+		return &sdk.Response{}, &timeoutError{api: api}
+	}
+	return &sdk.Response{StatusCode: http.StatusOK, Body: body}, nil
+}
+
+// serve answers the JSON-RPC request body sent to the access layer interface api
+func (s *Backend) serve(api string, body []byte) []byte {
+	var req rpcRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return s.reply(nil, nil, &rpcError{CodeInvalidRequest, err.Error()})
+	}
+	if strings.TrimPrefix(req.Method, s.namespace+"_") == req.Method || req.Method != s.namespace+"_"+api {
+		return s.reply(req.ID, nil, &rpcError{CodeMethodNotFound, "method not found: " + req.Method})
+	}
+	if err := s.verifier.Verify(body); err != nil {
+		s.log.Warn("baastest auth fail", "method", req.Method, "err", err)
+		return s.reply(req.ID, nil, &rpcError{CodeAuth, err.Error()})
+	}
+	var params []json.RawMessage
+	if len(req.Params) != 0 {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return s.reply(req.ID, nil, &rpcError{CodeInvalidParams, err.Error()})
+		}
+	}
+	if api == "getBaasSdkConf" {
+		// getBaasSdkConf replies with its own envelope
+		res, _ := json.Marshal(map[string]interface{}{
+			"code": 0,
+			"msg":  "success",
+			"data": map[string]interface{}{"chainid": s.chainID},
+		})
+		return res
+	}
+	result, rerr := s.handle(api, params)
+	return s.reply(req.ID, result, rerr)
+}
+
+func (s *Backend) reply(id, result interface{}, rerr *rpcError) []byte {
+	res := map[string]interface{}{"jsonrpc": "2.0", "id": id}
+	if rerr != nil {
+		res["error"] = rerr
+	} else {
+		res["result"] = result
+	}
+	data, _ := json.Marshal(res)
+	return data
+}
+
+func (s *Backend) handle(api string, params []json.RawMessage) (interface{}, *rpcError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch api {
+	case "getTransactionCount":
+		var addr common.Address
+		if err := parseParams(params, 1, &addr); err != nil {
+			return nil, err
+		}
+		return hexutil.EncodeUint64(s.nonces[addr]), nil
+	case "getBalance":
+		var addr common.Address
+		if err := parseParams(params, 1, &addr); err != nil {
+			return nil, err
+		}
+		return hexutil.EncodeBig(s.balance(addr)), nil
+	case "gasPrice":
+		return hexutil.EncodeBig(s.gasPrice), nil
+	case "blockNumber":
+		return hexutil.EncodeUint64(s.head().number), nil
+	case "estimateGas":
+		var args struct {
+			Data string `json:"data"`
+		}
+		if err := parseParams(params, 1, &args); err != nil {
+			return nil, err
+		}
+		// the SDK sends empty data as "0x0"
+		data := strings.TrimPrefix(args.Data, "0x")
+		if len(data)%2 == 1 {
+			data = "0" + data
+		}
+		return hexutil.EncodeUint64(intrinsicGas(common.Hex2Bytes(data))), nil
+	case "call":
+		var args struct {
+			From string `json:"from"`
+			To   string `json:"to"`
+			Data string `json:"data"`
+		}
+		if err := parseParams(params, 1, &args); err != nil {
+			return nil, err
+		}
+		if s.call == nil {
+			return "0x", nil
+		}
+		res, err := s.call(args.From, args.To, args.Data)
+		if err != nil {
+			return nil, &rpcError{CodeTxRejected, err.Error()}
+		}
+		return res, nil
+	case "sendRawTransaction":
+		var raw hexutil.Bytes
+		if err := parseParams(params, 1, &raw); err != nil {
+			return nil, err
+		}
+		if _, ok := s.takeFault(api, FaultNonceTooLow); ok {
+			return nil, &rpcError{CodeTxRejected, types.ErrNonceTooLow.Error()}
+		}
+		hash, err := s.sendRawTransaction(raw)
+		if err != nil {
+			return nil, &rpcError{CodeTxRejected, err.Error()}
+		}
+		return hash, nil
+	case "getTransactionByHash":
+		var hash common.Hash
+		if err := parseParams(params, 1, &hash); err != nil {
+			return nil, err
+		}
+		if rec, ok := s.txs[hash]; ok {
+			return rec.marshal(), nil
+		}
+		return nil, nil
+	case "getTransactionReceipt":
+		var hash common.Hash
+		if err := parseParams(params, 1, &hash); err != nil {
+			return nil, err
+		}
+		if rec, ok := s.txs[hash]; ok && rec.block != nil {
+			return rec.receipt(), nil
+		}
+		return nil, nil
+	case "getBlockByNumber":
+		var number string
+		if err := parseParams(params, 1, &number); err != nil {
+			return nil, err
+		}
+		b, err := s.blockByNumber(number)
+		if err != nil {
+			return nil, &rpcError{CodeInvalidParams, err.Error()}
+		}
+		if b == nil {
+			return nil, nil
+		}
+		return b.marshal(fullTx(params)), nil
+	case "getBlockByHash":
+		var hash common.Hash
+		if err := parseParams(params, 1, &hash); err != nil {
+			return nil, err
+		}
+		for _, b := range s.blocks {
+			if b.hash == hash {
+				return b.marshal(fullTx(params)), nil
+			}
+		}
+		return nil, nil
+	}
+	return nil, &rpcError{CodeMethodNotFound, "method not found: " + api}
+}
+
+// parseParams decodes the first len(vals) params into vals, at least min of them required
+func parseParams(params []json.RawMessage, min int, vals ...interface{}) *rpcError {
+	if len(params) < min {
+		return &rpcError{CodeInvalidParams, fmt.Sprintf("missing params, want %d got %d", min, len(params))}
+	}
+	for i, v := range vals {
+		if i >= len(params) {
+			break
+		}
+		if err := json.Unmarshal(params[i], v); err != nil {
+			return &rpcError{CodeInvalidParams, fmt.Sprintf("invalid param %d: %v", i, err)}
+		}
+	}
+	return nil
+}
+
+// fullTx reads the optional fullTx flag of getBlockBy*, sent as bool or "true"/"false"
+func fullTx(params []json.RawMessage) bool {
+	if len(params) < 2 {
+		return false
+	}
+	var v interface{}
+	json.Unmarshal(params[1], &v)
+	switch x := v.(type) {
+	case bool:
+		return x
+	case string:
+		b, _ := strconv.ParseBool(x)
+		return b
+	}
+	return false
+}
+
+// ------------------------------- chain -------------------------------
+var errNonceTooHigh = errors.New("nonce too high")
+
+const genesisTime = 1600000000
+
+func (b *block) cumulativeGas(index int) uint64 {
+	var gas uint64
+	for _, rec := range b.txs[:index+1] {
+		gas += rec.gasUsed
+	}
+	return gas
+}
+
+func (s *Backend) balance(addr common.Address) *big.Int {
+	if b, ok := s.balances[addr]; ok {
+		return new(big.Int).Set(b)
+	}
+	return new(big.Int)
+}
+
+func (s *Backend) head() *block {
+	return s.blocks[len(s.blocks)-1]
+}
+
+func (s *Backend) newBlock(txs []*txRecord) *block {
+	// block times are deterministic, one second apart from the genesis
+	b := &block{number: uint64(len(s.blocks)), time: genesisTime + int64(len(s.blocks)), txs: txs}
+	if b.number > 0 {
+		b.parent = s.head().hash
+	}
+	b.hash = crypto.Keccak256Hash(b.parent[:], []byte(strconv.FormatUint(b.number, 10)))
+	return b
+}
+
+func (s *Backend) blockByNumber(number string) (*block, error) {
+	switch number {
+	case "latest", "pending":
+		return s.head(), nil
+	case "earliest":
+		return s.blocks[0], nil
+	}
+	n, err := hexutil.DecodeUint64(number)
+	if err != nil {
+		return nil, err
+	}
+	if n >= uint64(len(s.blocks)) {
+		return nil, nil
+	}
+	return s.blocks[n], nil
+}
+
+// sendRawTransaction validates raw like a node would, applies it to the state and
+// adds it to the pending transactions
+func (s *Backend) sendRawTransaction(raw []byte) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := bal.DecodeBytes(raw, tx); err != nil {
+		return common.Hash{}, fmt.Errorf("decode transaction: %v", err)
+	}
+	if _, ok := s.txs[tx.Hash()]; ok {
+		return common.Hash{}, errors.New("known transaction")
+	}
+	if tx.SignParam().Cmp(s.signer) != 0 {
+		return common.Hash{}, fmt.Errorf("invalid sign param %v, want %v", tx.SignParam(), s.signer)
+	}
+	tx.SetSignHashType(s.signHash)
+	from, err := tx.Sender(types.MakeSTDSigner(s.signer))
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("%v: %v", types.ErrInvalidSender, err)
+	}
+	if tx.Value().Sign() < 0 {
+		return common.Hash{}, types.ErrNegativeValue
+	}
+	nonce := s.nonces[from]
+	if tx.Nonce() < nonce {
+		return common.Hash{}, types.ErrNonceTooLow
+	}
+	if tx.Nonce() > nonce {
+		return common.Hash{}, errNonceTooHigh
+	}
+	gasUsed := intrinsicGas(tx.Data())
+	if tx.Gas() < gasUsed {
+		return common.Hash{}, types.ErrIntrinsicGas
+	}
+	cost := new(big.Int).Mul(tx.GasPrice(), new(big.Int).SetUint64(tx.Gas()))
+	cost.Add(cost, tx.Value())
+	balance := s.balance(from)
+	if balance.Cmp(cost) < 0 {
+		return common.Hash{}, types.ErrInsufficientFunds
+	}
+	rec := &txRecord{tx: tx, from: from, gasUsed: gasUsed}
+	if _, ok := s.takeFault("sendRawTransaction", FaultOutOfGas); ok {
+		// runs out of gas: the whole gas limit is charged and the value is not transferred
+		rec.gasUsed, rec.failed = tx.Gas(), true
+	}
+	// charge the used gas only, the rest of the gas limit is refunded
+	fee := new(big.Int).Mul(tx.GasPrice(), new(big.Int).SetUint64(rec.gasUsed))
+	if !rec.failed {
+		fee.Add(fee, tx.Value())
+		if to := tx.To(); to != nil {
+			s.balances[*to] = s.balance(*to).Add(s.balance(*to), tx.Value())
+		}
+	}
+	s.balances[from] = balance.Sub(balance, fee)
+	s.nonces[from] = nonce + 1
+
+	s.txs[tx.Hash()] = rec
+	s.pending = append(s.pending, rec)
+	s.log.Debug("baastest tx accepted", "hash", tx.Hash(), "from", from, "failed", rec.failed)
+	if s.autoMine {
+		s.commit()
+	}
+	return tx.Hash(), nil
+}
+
+func (s *Backend) commit() uint64 {
+	b := s.newBlock(s.pending)
+	for i, rec := range s.pending {
+		rec.block, rec.index = b, i
+	}
+	s.pending = nil
+	s.blocks = append(s.blocks, b)
+	s.log.Debug("baastest block mined", "number", b.number, "txs", len(b.txs))
+	return b.number
+}
+
+func intrinsicGas(data []byte) uint64 {
+	gas := types.TxGas
+	for _, b := range data {
+		if b == 0 {
+			gas += types.TxDataZeroGas
+		} else {
+			gas += types.TxDataNonZeroGas
+		}
+	}
+	return gas
+}
+
+func (rec *txRecord) marshal() map[string]interface{} {
+	var res map[string]interface{}
+	data, _ := json.Marshal(rec.tx)
+	json.Unmarshal(data, &res)
+	res["from"] = rec.from
+	res["blockHash"], res["blockNumber"], res["transactionIndex"] = nil, nil, nil
+	if rec.block != nil {
+		res["blockHash"] = rec.block.hash
+		res["blockNumber"] = hexutil.EncodeUint64(rec.block.number)
+		res["transactionIndex"] = hexutil.EncodeUint64(uint64(rec.index))
+	}
+	return res
+}
+
+func (rec *txRecord) receipt() map[string]interface{} {
+	status := "0x1"
+	if rec.failed {
+		status = "0x0"
+	}
+	return map[string]interface{}{
+		"transactionHash":   rec.tx.Hash(),
+		"transactionIndex":  hexutil.EncodeUint64(uint64(rec.index)),
+		"blockHash":         rec.block.hash,
+		"blockNumber":       hexutil.EncodeUint64(rec.block.number),
+		"from":              rec.from,
+		"to":                rec.tx.To(),
+		"gasUsed":           hexutil.EncodeUint64(rec.gasUsed),
+		"cumulativeGasUsed": hexutil.EncodeUint64(rec.block.cumulativeGas(rec.index)),
+		"contractAddress":   nil,
+		"logs":              []interface{}{},
+		"status":            status,
+	}
+}
+
+func (b *block) marshal(fullTx bool) map[string]interface{} {
+	txs := make([]interface{}, len(b.txs))
+	var gasUsed uint64
+	for i, rec := range b.txs {
+		gasUsed += rec.gasUsed
+		if fullTx {
+			txs[i] = rec.marshal()
+		} else {
+			txs[i] = rec.tx.Hash()
+		}
+	}
+	return map[string]interface{}{
+		"number":       hexutil.EncodeUint64(b.number),
+		"hash":         b.hash,
+		"parentHash":   b.parent,
+		"timestamp":    hexutil.EncodeUint64(uint64(b.time)),
+		"gasUsed":      hexutil.EncodeUint64(gasUsed),
+		"transactions": txs,
+	}
+}
@@ -0,0 +1,324 @@
+package baastest_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	sdk "github.com/XunleiBlockchain/baas-sdk-go"
+	"github.com/XunleiBlockchain/baas-sdk-go/baastest"
+	"github.com/XunleiBlockchain/tc-libs/common"
+)
+
+var testTo = common.HexToAddress("0x33d4fcb75ce608920c7e5755304c282141dfc4dc")
+
+// newTestSDK returns a SDK on b with a funded unlocked account, BaaS calls are not
+// retried unless cfg.Retry is set
+func newTestSDK(t *testing.T, cfg *sdk.Config, b *baastest.Backend) (*sdk.SDKImpl, common.Address) {
+	t.Helper()
+	if cfg.Retry == 0 {
+		cfg.Retry = 1
+	}
+	s, err := sdk.NewSDK(cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Close)
+	res, xerr := s.NewAccount([]interface{}{"passwd"})
+	if xerr != nil && xerr.Code != 0 {
+		t.Fatal(xerr)
+	}
+	from := res.(common.Address)
+	b.SetBalance(from, new(big.Int).Exp(big.NewInt(10), big.NewInt(20), nil))
+	return s, from
+}
+
+func transfer(s *sdk.SDKImpl, from common.Address, value int64) (interface{}, *sdk.Error) {
+	return s.SendTransaction([]interface{}{map[string]interface{}{
+		"from":  from.Hex(),
+		"to":    testTo.Hex(),
+		"value": big.NewInt(value).String(),
+	}})
+}
+
+func receipt(t *testing.T, s *sdk.SDKImpl, hash interface{}) map[string]interface{} {
+	t.Helper()
+	res, xerr := s.GetTransactionReceipt([]interface{}{hash})
+	if xerr != nil && xerr.Code != 0 {
+		t.Fatal(xerr)
+	}
+	r, _ := res.(map[string]interface{})
+	return r
+}
+
+func TestSendTransactionReceipt(t *testing.T) {
+	b := baastest.NewBackend(nil)
+	s, from := newTestSDK(t, b.Config(t.TempDir()), b)
+
+	hash, xerr := transfer(s, from, 1000)
+	if xerr.Code != 0 {
+		t.Fatalf("send: %v", xerr)
+	}
+	if b.Pending() != 1 {
+		t.Fatalf("pending = %d, want 1", b.Pending())
+	}
+	if r := receipt(t, s, hash); r != nil {
+		t.Fatalf("receipt before mined: %v", r)
+	}
+	b.Commit()
+	r := receipt(t, s, hash)
+	if r == nil || r["status"] != "0x1" || r["blockNumber"] != "0x1" {
+		t.Fatalf("receipt = %v", r)
+	}
+	if got := b.Balance(testTo); got.Int64() != 1000 {
+		t.Errorf("recipient balance = %v, want 1000", got)
+	}
+	if got := b.Nonce(from); got != 1 {
+		t.Errorf("nonce = %d, want 1", got)
+	}
+
+	// the next send picks up the nonce from the chain
+	hash2, xerr := transfer(s, from, 1)
+	if xerr.Code != 0 {
+		t.Fatalf("second send: %v", xerr)
+	}
+	b.Commit()
+	if r := receipt(t, s, hash2); r == nil || r["status"] != "0x1" {
+		t.Fatalf("second receipt = %v", r)
+	}
+}
+
+func TestSignHashPerSDK(t *testing.T) {
+	// SDKs of different sign hashes run side by side, each chain recovers its senders
+	for _, hash := range []string{"sm3", "keccak256"} {
+		b := baastest.NewBackend(&baastest.Options{SignHash: hash})
+		b.SetAutoMine(true)
+		s, from := newTestSDK(t, b.Config(t.TempDir()), b)
+		tx, xerr := transfer(s, from, 1)
+		if xerr.Code != 0 {
+			t.Fatalf("%s: send: %v", hash, xerr)
+		}
+		if r := receipt(t, s, tx); r == nil || r["status"] != "0x1" {
+			t.Fatalf("%s: receipt = %v", hash, r)
+		}
+	}
+
+	// a chain of the other hash recovers another sender and refuses the transaction
+	b := baastest.NewBackend(&baastest.Options{SignHash: "sm3"})
+	cfg := b.Config(t.TempDir())
+	cfg.SignHash = "keccak256"
+	s, from := newTestSDK(t, cfg, b)
+	if _, xerr := transfer(s, from, 1); xerr.Code == 0 {
+		t.Fatal("keccak256 signed transaction accepted by a sm3 chain")
+	}
+}
+
+func TestFaultTimeout(t *testing.T) {
+	b := baastest.NewBackend(nil)
+	s, from := newTestSDK(t, b.Config(t.TempDir()), b)
+	b.SetAutoMine(true)
+
+	b.InjectFault("sendRawTransaction", baastest.FaultTimeout, 1)
+	if _, xerr := transfer(s, from, 1); xerr.Code != sdk.ErrRpcSendTransaction.Code {
+		t.Fatalf("send = %v, want code %d", xerr, sdk.ErrRpcSendTransaction.Code)
+	}
+	if b.Nonce(from) != 0 {
+		t.Fatal("timed out transaction reached the chain")
+	}
+
+	// with retries the timeout is absorbed
+	cfg := b.Config(t.TempDir())
+	cfg.Retry = 2
+	s2, from2 := newTestSDK(t, cfg, b)
+	b.InjectFault("sendRawTransaction", baastest.FaultTimeout, 1)
+	hash, xerr := transfer(s2, from2, 1)
+	if xerr.Code != 0 {
+		t.Fatalf("send with retry: %v", xerr)
+	}
+	if r := receipt(t, s2, hash); r == nil || r["status"] != "0x1" {
+		t.Fatalf("receipt = %v", r)
+	}
+}
+
+func TestFaultLostReplyRetryAuthV2(t *testing.T) {
+	b := baastest.NewBackend(nil)
+	cfg := b.Config(t.TempDir())
+	cfg.AuthScheme = sdk.AuthSchemeV2
+	cfg.Retry = 2
+	s, from := newTestSDK(t, cfg, b)
+
+	// the retry is signed anew, the nonce of the served attempt is not replayed
+	b.InjectFault("getBalance", baastest.FaultLostReply, 1)
+	res, xerr := s.GetBalance([]interface{}{from.Hex()})
+	if xerr != nil && xerr.Code != 0 {
+		t.Fatalf("getBalance with retry: %v", xerr)
+	}
+	if res == nil {
+		t.Fatal("getBalance returned nil")
+	}
+}
+
+func TestFaultNonceTooLow(t *testing.T) {
+	b := baastest.NewBackend(nil)
+	s, from := newTestSDK(t, b.Config(t.TempDir()), b)
+
+	b.InjectFault("sendRawTransaction", baastest.FaultNonceTooLow, 1)
+	_, xerr := transfer(s, from, 1)
+	if xerr.Code != baastest.CodeTxRejected {
+		t.Fatalf("send = %v, want code %d", xerr, baastest.CodeTxRejected)
+	}
+	if b.Pending() != 0 {
+		t.Fatal("rejected transaction is pending")
+	}
+	// the fault is consumed, the nonce was not used
+	if _, xerr = transfer(s, from, 1); xerr.Code != 0 {
+		t.Fatalf("send after fault: %v", xerr)
+	}
+}
+
+func TestFaultOutOfGas(t *testing.T) {
+	b := baastest.NewBackend(nil)
+	s, from := newTestSDK(t, b.Config(t.TempDir()), b)
+	before := b.Balance(from)
+
+	b.InjectFault("sendRawTransaction", baastest.FaultOutOfGas, 1)
+	hash, xerr := transfer(s, from, 1000)
+	if xerr.Code != 0 {
+		t.Fatalf("send = %v, out of gas is accepted", xerr)
+	}
+	b.Commit()
+	r := receipt(t, s, hash)
+	if r == nil || r["status"] != "0x0" {
+		t.Fatalf("receipt = %v, want status 0x0", r)
+	}
+	if got := b.Balance(testTo); got.Sign() != 0 {
+		t.Errorf("recipient balance = %v, value must not be transferred", got)
+	}
+	if b.Balance(from).Cmp(before) != 0 {
+		t.Errorf("sender balance = %v, want %v: value must not be debited at gas price 0", b.Balance(from), before)
+	}
+	// the whole gas limit is used up
+	tx, xerr := s.GetTransactionByHash([]interface{}{from.Hex(), hash})
+	if xerr.Code != 0 {
+		t.Fatal(xerr)
+	}
+	if gas := tx.(map[string]interface{})["gas"]; r["gasUsed"] != gas {
+		t.Errorf("gasUsed = %v, want the gas limit %v", r["gasUsed"], gas)
+	}
+	if b.Nonce(from) != 1 {
+		t.Errorf("nonce = %d, want 1", b.Nonce(from))
+	}
+}
+
+// closeCounter is a CredentialProvider counting its Close calls
+type closeCounter struct {
+	sdk.StaticCredentials
+	closed int
+}
+
+func (c *closeCounter) Close() { c.closed++ }
+
+// brokenJournal fails to load the journaled transactions
+type brokenJournal struct{}
+
+func (brokenJournal) Put(*sdk.JournalEntry) error { return nil }
+func (brokenJournal) Unfinished() ([]*sdk.JournalEntry, error) {
+	return nil, errors.New("broken journal")
+}
+
+func TestNewSDKFailureCloses(t *testing.T) {
+	b := baastest.NewBackend(nil)
+	cfg := b.Config(t.TempDir())
+	creds := &closeCounter{StaticCredentials: sdk.StaticCredentials(cfg.AuthInfo)}
+	cfg.Credentials = creds
+	cfg.Journal = brokenJournal{}
+	if _, err := sdk.NewSDK(cfg, nil); err == nil {
+		t.Fatal("NewSDK with a broken journal succeeded")
+	}
+	if creds.closed != 1 {
+		t.Fatalf("credentials closed %d times, want 1", creds.closed)
+	}
+
+	// the crypto of the failed SDK has been released
+	cfg = b.Config(t.TempDir())
+	cfg.CryptoType = "gm"
+	s, err := sdk.NewSDK(cfg, nil)
+	if err != nil {
+		t.Fatalf("NewSDK after a failed one: %v", err)
+	}
+	s.Close()
+}
+
+// copyFile copies src to dst, an absent src copies nothing
+func copyFile(t *testing.T, src, dst string) {
+	t.Helper()
+	data, err := ioutil.ReadFile(src)
+	if os.IsNotExist(err) {
+		return
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(dst, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIdempotencyCrashAfterBroadcast(t *testing.T) {
+	b := baastest.NewBackend(nil)
+	b.SetAutoMine(true)
+	dir, crashed := t.TempDir(), t.TempDir()
+	keystore := filepath.Join(dir, "keystore")
+	journalPath, storePath := filepath.Join(dir, "journal"), filepath.Join(dir, "idempotency")
+
+	// the files as a crash right after the broadcast leaves them
+	snapshot := func(next sdk.RoundTripper) sdk.RoundTripper {
+		return sdk.RoundTripperFunc(func(req *sdk.Request) (*sdk.Response, error) {
+			resp, err := next.RoundTrip(req)
+			if req.API == "sendRawTransaction" {
+				copyFile(t, journalPath, filepath.Join(crashed, "journal"))
+				copyFile(t, storePath, filepath.Join(crashed, "idempotency"))
+			}
+			return resp, err
+		})
+	}
+	open := func(dir string, mws ...sdk.Middleware) (*sdk.SDKImpl, common.Address) {
+		t.Helper()
+		journal, err := sdk.NewFileJournal(filepath.Join(dir, "journal"), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		store, err := sdk.NewFileIdempotencyStore(filepath.Join(dir, "idempotency"), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg := b.Config(keystore)
+		cfg.Journal, cfg.Idempotency, cfg.Middlewares = journal, store, mws
+		return newTestSDK(t, cfg, b)
+	}
+
+	s, from := open(dir, snapshot)
+	ctx := sdk.ContextWithIdempotencyKey(context.Background(), "order-1")
+	hash, xerr := transfer(s.WithContext(ctx), from, 1)
+	if xerr.Code != 0 {
+		t.Fatalf("send: %v", xerr)
+	}
+	s.Close()
+
+	// restarted on the crashed files, the retry of the client gets the first transaction
+	s2, _ := open(crashed)
+	hash2, xerr := transfer(s2.WithContext(ctx), from, 1)
+	if xerr.Code != 0 {
+		t.Fatalf("retry after crash: %v", xerr)
+	}
+	if hash2 != hash {
+		t.Errorf("retry after crash = %v, want %v", hash2, hash)
+	}
+	if n := b.Nonce(from); n != 1 {
+		t.Errorf("%d transactions reached the chain, want 1", n)
+	}
+}
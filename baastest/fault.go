@@ -0,0 +1,67 @@
+package baastest
+
+import "fmt"
+
+// Fault is a failure injected into the next request of an access layer interface
+type Fault int
+
+const (
+	// FaultTimeout fails the request with a timeout error before it reaches the chain,
+	// works for every interface
+	FaultTimeout Fault = iota + 1
+	// FaultNonceTooLow rejects sendRawTransaction with nonce too low
+	FaultNonceTooLow
+	// FaultOutOfGas accepts the transaction of sendRawTransaction but it runs out of
+	// gas: the gas limit is charged, the value is not transferred and the receipt
+	// status is 0x0
+	FaultOutOfGas
+	// FaultLostReply serves the request, so a transaction reaches the chain, then
+	// fails it with a timeout error as if the reply were lost, works for every interface
+	FaultLostReply
+)
+
+func (f Fault) String() string {
+	switch f {
+	case FaultTimeout:
+		return "timeout"
+	case FaultNonceTooLow:
+		return "nonce too low"
+	case FaultOutOfGas:
+		return "out of gas"
+	case FaultLostReply:
+		return "lost reply"
+	}
+	return fmt.Sprintf("Fault(%d)", int(f))
+}
+
+// timeoutError is returned for FaultTimeout and FaultLostReply, it implements net.Error
+type timeoutError struct {
+	api string
+}
+
+func (e *timeoutError) Error() string   { return "baastest: " + e.api + " timeout" }
+func (e *timeoutError) Timeout() bool   { return true }
+func (e *timeoutError) Temporary() bool { return true }
+
+// InjectFault makes the next times requests of the access layer interface api
+// (e.g. sendRawTransaction) fail with f
+func (s *Backend) InjectFault(api string, f Fault, times int) {
+	s.faultMu.Lock()
+	defer s.faultMu.Unlock()
+	for i := 0; i < times; i++ {
+		s.faults[api] = append(s.faults[api], f)
+	}
+}
+
+// takeFault consumes an injected fault f of api
+func (s *Backend) takeFault(api string, f Fault) (Fault, bool) {
+	s.faultMu.Lock()
+	defer s.faultMu.Unlock()
+	for i, x := range s.faults[api] {
+		if x == f {
+			s.faults[api] = append(s.faults[api][:i], s.faults[api][i+1:]...)
+			return f, true
+		}
+	}
+	return 0, false
+}
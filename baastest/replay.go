@@ -0,0 +1,224 @@
+package baastest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	sdk "github.com/XunleiBlockchain/baas-sdk-go"
+)
+
+// Interaction is one recorded BaaS request/response pair of a golden file
+type Interaction struct {
+	Method   string          `json:"method"`
+	Params   json.RawMessage `json:"params"`
+	Status   int             `json:"status"`
+	Response json.RawMessage `json:"response"`
+}
+
+type goldenFile struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// scrubbedKeys are removed from recorded requests and responses
+var scrubbedKeys = map[string]bool{
+	"auth":     true,
+	"key":      true,
+	"sign":     true,
+	"passwd":   true,
+	"password": true,
+	"mnemonic": true,
+}
+
+// Recorder records the BaaS interactions passing through its middleware, auth
+// signatures and secrets are scrubbed. Save writes them as a golden file for Replayer.
+type Recorder struct {
+	path string
+
+	mu           sync.Mutex
+	interactions []*Interaction
+}
+
+// NewRecorder returns a Recorder saving to path
+func NewRecorder(path string) *Recorder {
+	return &Recorder{path: path}
+}
+
+// Middleware returns the recording middleware, add it to Config.Middlewares of an
+// SDK talking to a real BaaS access layer
+func (r *Recorder) Middleware() sdk.Middleware {
+	return func(next sdk.RoundTripper) sdk.RoundTripper {
+		return sdk.RoundTripperFunc(func(req *sdk.Request) (*sdk.Response, error) {
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				return resp, err
+			}
+			method, params, perr := parseRequest(req.Body)
+			if perr != nil {
+				return resp, err
+			}
+			r.mu.Lock()
+			r.interactions = append(r.interactions, &Interaction{
+				Method:   method,
+				Params:   params,
+				Status:   resp.StatusCode,
+				Response: scrubJSON(resp.Body),
+			})
+			r.mu.Unlock()
+			return resp, err
+		})
+	}
+}
+
+// Save writes the recorded interactions to the golden file
+func (r *Recorder) Save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	data, err := json.MarshalIndent(&goldenFile{Interactions: r.interactions}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.path, append(data, '\n'), 0644)
+}
+
+// Replayer is a sdk.RoundTripper answering requests from a golden file, set it as
+// Config.Transport. Requests are matched by method and normalized params; repeated
+// requests get the recorded responses in order, then the last one again.
+type Replayer struct {
+	mu    sync.Mutex
+	queue map[string][]*Interaction
+}
+
+// NewReplayer loads the golden file at path
+func NewReplayer(path string) (*Replayer, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var golden goldenFile
+	if err := json.Unmarshal(data, &golden); err != nil {
+		return nil, fmt.Errorf("baastest: golden file %s: %v", path, err)
+	}
+	r := &Replayer{queue: make(map[string][]*Interaction)}
+	for _, it := range golden.Interactions {
+		key, err := matchKey(it.Method, it.Params)
+		if err != nil {
+			return nil, fmt.Errorf("baastest: golden file %s: %v", path, err)
+		}
+		r.queue[key] = append(r.queue[key], it)
+	}
+	return r, nil
+}
+
+// RoundTrip implements sdk.RoundTripper
+func (r *Replayer) RoundTrip(req *sdk.Request) (*sdk.Response, error) {
+	method, params, err := parseRequest(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	key, err := matchKey(method, params)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	q := r.queue[key]
+	if len(q) == 0 {
+		return nil, fmt.Errorf("baastest: no recorded interaction for %s %s", method, params)
+	}
+	it := q[0]
+	if len(q) > 1 {
+		r.queue[key] = q[1:]
+	}
+	status := it.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	return &sdk.Response{StatusCode: status, Body: []byte(it.Response)}, nil
+}
+
+func parseRequest(body []byte) (string, json.RawMessage, error) {
+	var req struct {
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return "", nil, err
+	}
+	return req.Method, scrubJSON(req.Params), nil
+}
+
+// matchKey normalizes params: object keys sorted, hex strings lower-cased
+func matchKey(method string, params json.RawMessage) (string, error) {
+	if len(params) == 0 {
+		return method, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(params))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(normalize(v))
+	if err != nil {
+		return "", err
+	}
+	return method + " " + string(data), nil
+}
+
+func normalize(v interface{}) interface{} {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		for k, e := range x {
+			x[k] = normalize(e)
+		}
+	case []interface{}:
+		for i, e := range x {
+			x[i] = normalize(e)
+		}
+	case string:
+		if strings.HasPrefix(x, "0x") || strings.HasPrefix(x, "0X") {
+			return strings.ToLower(x)
+		}
+	}
+	return v
+}
+
+// scrubJSON drops scrubbedKeys from a JSON document, data that is not JSON is
+// recorded as a JSON string
+func scrubJSON(data []byte) json.RawMessage {
+	if len(data) == 0 {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		res, _ := json.Marshal(string(data))
+		return res
+	}
+	res, _ := json.Marshal(scrub(v))
+	return res
+}
+
+func scrub(v interface{}) interface{} {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		for k, e := range x {
+			if scrubbedKeys[strings.ToLower(k)] {
+				delete(x, k)
+				continue
+			}
+			x[k] = scrub(e)
+		}
+	case []interface{}:
+		for i, e := range x {
+			x[i] = scrub(e)
+		}
+	}
+	return v
+}
@@ -0,0 +1,151 @@
+package baastest_test
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	sdk "github.com/XunleiBlockchain/baas-sdk-go"
+	"github.com/XunleiBlockchain/baas-sdk-go/baastest"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of testdata")
+
+const (
+	goldenReplay = "testdata/replay.json"
+	// recorded with upper case hex, replayed with lower case
+	testHash = "0xAB00000000000000000000000000000000000000000000000000000000000001"
+)
+
+// replayCalls makes the calls recorded in goldenReplay and returns their results
+func replayCalls(t *testing.T, s *sdk.SDKImpl, hash string) []interface{} {
+	t.Helper()
+	number, xerr := s.BlockNumber()
+	if xerr != nil && xerr.Code != 0 {
+		t.Fatalf("blockNumber: %v", xerr)
+	}
+	receipt, xerr := s.GetTransactionReceipt([]interface{}{hash})
+	if xerr != nil && xerr.Code != 0 {
+		t.Fatalf("getTransactionReceipt: %v", xerr)
+	}
+	return []interface{}{number, receipt}
+}
+
+func TestRecordReplay(t *testing.T) {
+	b := baastest.NewBackend(nil)
+	path := filepath.Join(t.TempDir(), "replay.json")
+	rec := baastest.NewRecorder(path)
+	cfg := b.Config(t.TempDir())
+	cfg.AuthScheme = sdk.AuthSchemeV2
+	cfg.Middlewares = []sdk.Middleware{rec.Middleware()}
+	s, err := sdk.NewSDK(cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	want := replayCalls(t, s, testHash)
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *update {
+		if err := ioutil.WriteFile(goldenReplay, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	golden, err := ioutil.ReadFile(goldenReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, golden) {
+		t.Errorf("recorded:\n%s\nwant %s, run go test -update if the change is intended", got, goldenReplay)
+	}
+	for _, secret := range []string{`"auth"`, cfg.AuthInfo.Key} {
+		if bytes.Contains(got, []byte(secret)) {
+			t.Errorf("recorded file contains %s", secret)
+		}
+	}
+
+	// the golden file answers the same calls without the backend
+	r, err := baastest.NewReplayer(goldenReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg = b.Config(t.TempDir())
+	cfg.AuthScheme = sdk.AuthSchemeV1
+	cfg.Transport = r
+	s2, err := sdk.NewSDK(cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s2.Close()
+	res := replayCalls(t, s2, strings.ToLower(testHash))
+	for i := range want {
+		if res[i] != want[i] {
+			t.Errorf("replayed result %d = %v, want %v", i, res[i], want[i])
+		}
+	}
+	if _, xerr := s2.GetTransactionReceipt([]interface{}{"0x" + strings.Repeat("0", 64)}); xerr.Code == 0 {
+		t.Error("unrecorded request answered")
+	}
+}
+
+// fakeTransport answers every request with body
+type fakeTransport string
+
+func (f fakeTransport) RoundTrip(req *sdk.Request) (*sdk.Response, error) {
+	return &sdk.Response{StatusCode: http.StatusOK, Body: []byte(f)}, nil
+}
+
+func TestRecorderScrub(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scrub.json")
+	rec := baastest.NewRecorder(path)
+	rt := rec.Middleware()(fakeTransport(`{"id":1,"result":{"address":"0xAbCd","Mnemonic":"abandon about","key":"k1"}}`))
+	body := `{"jsonrpc":"2.0","id":1,"method":"tcapi_importKey","params":[{"from":"0xAbCd","passwd":"p1"}],"auth":{"sign":"s1"}}`
+	if _, err := rt.RoundTrip(&sdk.Request{Body: []byte(body)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"p1", "s1", "k1", "abandon", `"auth"`} {
+		if bytes.Contains(data, []byte(secret)) {
+			t.Errorf("recorded file contains %s:\n%s", secret, data)
+		}
+	}
+
+	// scrubbed fields and the case of hex strings do not take part in matching
+	r, err := baastest.NewReplayer(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, body := range []string{
+		body,
+		`{"method":"tcapi_importKey","params":[{"passwd":"other","from":"0xabcd"}]}`,
+		`{"method":"tcapi_importKey","params":[{"from":"0XABCD"}]}`,
+	} {
+		resp, err := r.RoundTrip(&sdk.Request{Body: []byte(body)})
+		if err != nil {
+			t.Errorf("%s: %v", body, err)
+			continue
+		}
+		if !bytes.Contains(resp.Body, []byte(`"0xAbCd"`)) {
+			t.Errorf("%s: response %s", body, resp.Body)
+		}
+	}
+	if _, err := r.RoundTrip(&sdk.Request{Body: []byte(`{"method":"tcapi_importKey","params":[{"from":"0xabce"}]}`)}); err == nil {
+		t.Error("other params matched")
+	}
+}
@@ -0,0 +1,64 @@
+package baastest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	sdk "github.com/XunleiBlockchain/baas-sdk-go"
+)
+
+// Server serves a Backend as a fake BaaS access layer on a local port
+type Server struct {
+	*httptest.Server
+	*Backend
+}
+
+// NewServer starts a fake BaaS access layer, Close it when done. Unlike NewBackend,
+// every accepted transaction is mined into a new block at once.
+func NewServer(opts *Options) *Server {
+	s := &Server{Backend: NewBackend(opts)}
+	s.SetAutoMine(true)
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// Host returns the host:port of the server, used as Config.XHost
+func (s *Server) Host() string {
+	return strings.TrimPrefix(s.URL, "http://")
+}
+
+// Config returns a sdk.Config talking to the server with keystore as the key directory
+func (s *Server) Config(keystore string) *sdk.Config {
+	cfg := s.Backend.Config(keystore)
+	cfg.RPCProtocal = "http"
+	cfg.XHost = s.Host()
+	cfg.Transport = nil
+	return cfg
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method != "POST" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	api := strings.Trim(r.URL.Path, "/")
+	if _, ok := s.takeFault(api, FaultTimeout); ok {
+		// let the client see no reply
+		http.Error(w, "baastest: "+api+" timeout", http.StatusGatewayTimeout)
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	reply := s.serve(api, body)
+	if _, ok := s.takeFault(api, FaultLostReply); ok {
+		http.Error(w, "baastest: "+api+" timeout", http.StatusGatewayTimeout)
+		return
+	}
+	w.Write(reply)
+}
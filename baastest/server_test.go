@@ -0,0 +1,145 @@
+package baastest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	sdk "github.com/XunleiBlockchain/baas-sdk-go"
+	"github.com/XunleiBlockchain/baas-sdk-go/baastest"
+)
+
+// captured records the requests the SDK sends through its middleware chain
+type captured struct {
+	mu   sync.Mutex
+	reqs map[string]sdk.Request // 按接入层接口 最近一次
+}
+
+func (c *captured) middleware(next sdk.RoundTripper) sdk.RoundTripper {
+	return sdk.RoundTripperFunc(func(req *sdk.Request) (*sdk.Response, error) {
+		c.mu.Lock()
+		c.reqs[req.API] = *req
+		c.mu.Unlock()
+		return next.RoundTrip(req)
+	})
+}
+
+func (c *captured) get(t *testing.T, api string) sdk.Request {
+	t.Helper()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	req, ok := c.reqs[api]
+	if !ok {
+		t.Fatalf("no %s request captured", api)
+	}
+	return req
+}
+
+// post sends body to the server as is and returns the JSON-RPC error code, 0 on success
+func post(t *testing.T, url string, body []byte) (int, string) {
+	t.Helper()
+	resp, err := http.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, _ := ioutil.ReadAll(resp.Body)
+	var reply struct {
+		Error *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(data, &reply); err != nil {
+		t.Fatalf("reply %s: %v", data, err)
+	}
+	if reply.Error == nil {
+		return 0, ""
+	}
+	return reply.Error.Code, reply.Error.Message
+}
+
+// withParam returns the request body with params[0] replaced by v, the auth kept
+func withParam(t *testing.T, body []byte, v interface{}) []byte {
+	t.Helper()
+	var req map[string]interface{}
+	if err := json.Unmarshal(body, &req); err != nil {
+		t.Fatal(err)
+	}
+	req["params"].([]interface{})[0] = v
+	data, _ := json.Marshal(req)
+	return data
+}
+
+func TestServer(t *testing.T) {
+	for _, scheme := range []string{sdk.AuthSchemeV1, sdk.AuthSchemeV2} {
+		t.Run(scheme, func(t *testing.T) {
+			srv := baastest.NewServer(nil)
+			defer srv.Close()
+			rec := &captured{reqs: make(map[string]sdk.Request)}
+			cfg := srv.Config(t.TempDir())
+			cfg.AuthScheme = scheme
+			cfg.Middlewares = []sdk.Middleware{rec.middleware}
+			s, from := newTestSDK(t, cfg, srv.Backend)
+
+			// the SDK reaches the server over HTTP
+			if got := rec.get(t, "getBaasSdkConf").URL; !strings.HasPrefix(got, srv.URL+"/") {
+				t.Fatalf("request url %s, want under %s", got, srv.URL)
+			}
+			hash, xerr := transfer(s, from, 1000)
+			if xerr.Code != 0 {
+				t.Fatalf("send: %v", xerr)
+			}
+			if r := receipt(t, s, hash); r == nil || r["status"] != "0x1" {
+				t.Fatalf("receipt = %v", r)
+			}
+			if _, xerr := s.GetBalance([]interface{}{from.Hex()}); xerr.Code != 0 {
+				t.Fatalf("getBalance: %v", xerr)
+			}
+			if srv.Balance(testTo).Int64() != 1000 {
+				t.Fatalf("balance = %v, want 1000", srv.Balance(testTo))
+			}
+
+			// the server verifies the auth of what it receives
+			req := rec.get(t, "getBalance")
+			if code, msg := post(t, req.URL, withParam(t, req.Body, testTo.Hex())); code != baastest.CodeAuth {
+				t.Errorf("tampered params: code %d %s, want %d", code, msg, baastest.CodeAuth)
+			}
+			code, msg := post(t, req.URL, req.Body)
+			switch scheme {
+			case sdk.AuthSchemeV1:
+				// v1 carries no timestamp or nonce
+				if code != 0 {
+					t.Errorf("v1 resent: code %d %s", code, msg)
+				}
+			case sdk.AuthSchemeV2:
+				if code != baastest.CodeAuth || !strings.Contains(msg, "replayed") {
+					t.Errorf("v2 replayed: code %d %s, want %d nonce replayed", code, msg, baastest.CodeAuth)
+				}
+			}
+		})
+	}
+}
+
+func TestServerRejectsWrongKey(t *testing.T) {
+	srv := baastest.NewServer(nil)
+	defer srv.Close()
+	for _, scheme := range []string{sdk.AuthSchemeV1, sdk.AuthSchemeV2} {
+		cfg := srv.Config(t.TempDir())
+		cfg.AuthScheme = scheme
+		cfg.AuthInfo.Key = "wrong-key"
+		cfg.Retry = 1
+		s, err := sdk.NewSDK(cfg, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, xerr := s.BlockNumber(); xerr.Code != baastest.CodeAuth {
+			t.Errorf("%s: blockNumber with a wrong key = %v, want code %d", scheme, xerr, baastest.CodeAuth)
+		}
+		s.Close()
+	}
+}
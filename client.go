@@ -1,13 +1,16 @@
 package sdk
 
 import (
-	"crypto/md5"
-	"crypto/sha256"
+	"context"
 	"encoding/json"
 	"fmt"
 	"math/big"
+	"net/http"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/XunleiBlockchain/tc-libs/common"
 )
 
 var (
@@ -23,7 +26,13 @@ type client struct {
 	xHost     string
 	nameSpace string
 
-	auth *AuthInfo
+	creds      CredentialProvider
+	authScheme string
+	log        Logger
+	metrics    Metrics
+	tracer     Tracer
+	transport  RoundTripper
+	onSent     func(hash common.Hash) // 交易被BaaS接收后调用 用于pendingTxStatus订阅
 }
 
 func defaultClient() *client {
@@ -32,11 +41,21 @@ func defaultClient() *client {
 		protocal:  defaultProtocal,
 		xHost:     defaultXHost,
 		nameSpace: defaultNS,
+		metrics:   nopMetrics{},
+		tracer:    nopTracer{},
 	}
 }
 
-func newClient(cfg *Config) (*client, error) {
-	if cfg.AuthInfo.ChainID == "" || cfg.AuthInfo.ID == "" || cfg.AuthInfo.Key == "" {
+func newClient(cfg *Config, log Logger) (*client, error) {
+	creds := cfg.Credentials
+	if creds == nil {
+		creds = StaticCredentials(cfg.AuthInfo)
+	}
+	auth, err := creds.Credentials(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("newClient: Credentials error: %v", err)
+	}
+	if !auth.Active(time.Now()).complete() {
 		return nil, fmt.Errorf("newClient: AuthInfo empty")
 	}
 	cli := defaultClient()
@@ -52,7 +71,27 @@ func newClient(cfg *Config) (*client, error) {
 	if len(cfg.Namespace) > 0 {
 		cli.nameSpace = cfg.Namespace
 	}
-	cli.auth = &cfg.AuthInfo
+	cli.creds = creds
+	switch cfg.AuthScheme {
+	case "":
+		cli.authScheme = AuthSchemeV1
+	case AuthSchemeV1, AuthSchemeV2:
+		cli.authScheme = cfg.AuthScheme
+	default:
+		return nil, fmt.Errorf("newClient: invalid AuthScheme %s", cfg.AuthScheme)
+	}
+	cli.log = log
+	if cfg.Metrics != nil {
+		cli.metrics = cfg.Metrics
+	}
+	if cfg.Tracer != nil {
+		cli.tracer = cfg.Tracer
+	}
+	var transport RoundTripper = &httpTransport{log: log, host: cli.xHost}
+	if cfg.Transport != nil {
+		transport = cfg.Transport
+	}
+	cli.transport = chain(transport, cfg.Middlewares...)
 	return cli, nil
 }
 
@@ -64,16 +103,16 @@ type rpcReply struct {
 	Err     Error       `json:"error"`
 }
 
-func (c *client) getNonce(addr string) (nonce uint64, xerr *Error) {
+func (c *client) getNonce(ctx context.Context, addr string) (nonce uint64, xerr *Error) {
 	params := []interface{}{addr, "pending"}
-	reply, err := c.rpcCall(c.nameSpace+"_getTransactionCount", params)
+	reply, err := c.rpcCall(ctx, &rpcRequest{method: c.nameSpace + "_getTransactionCount", params: params})
 	if err != nil {
-		sdklog.Error("getTransactionCount error.", "err", err)
+		c.log.Error("getTransactionCount error.", "err", err)
 		return 0, ErrRpcGetNonce.Join(err)
 	}
 	var res rpcReply
 	json.Unmarshal(reply, &res)
-	sdklog.Info("getTransactionCount.", "params", params, "reply", string(reply))
+	c.log.Info("getTransactionCount.", "params", params, "reply", Payload(reply))
 	if res.Result != nil {
 		nonce, err = strconv.ParseUint(res.Result.(string), 0, 64)
 		if err != nil {
@@ -84,16 +123,16 @@ func (c *client) getNonce(addr string) (nonce uint64, xerr *Error) {
 	return
 }
 
-func (c *client) getBlockNumber() (nonce uint64, xerr *Error) {
+func (c *client) getBlockNumber(ctx context.Context) (nonce uint64, xerr *Error) {
 	params := []interface{}{}
-	reply, err := c.rpcCall(c.nameSpace+"_blockNumber", params)
+	reply, err := c.rpcCall(ctx, &rpcRequest{method: c.nameSpace + "_blockNumber", params: params})
 	if err != nil {
-		sdklog.Error("get blockNumber error.", "err", err)
+		c.log.Error("get blockNumber error.", "err", err)
 		return 0, ErrRpcBlockNumber.Join(err)
 	}
 	var res rpcReply
 	json.Unmarshal(reply, &res)
-	sdklog.Info("get blockNumber.", "params", params, "reply", string(reply))
+	c.log.Info("get blockNumber.", "params", params, "reply", Payload(reply))
 	if res.Result != nil {
 		nonce, err = strconv.ParseUint(res.Result.(string), 0, 64)
 		if err != nil {
@@ -104,16 +143,16 @@ func (c *client) getBlockNumber() (nonce uint64, xerr *Error) {
 	return
 }
 
-func (c *client) getBalance(addr string) (balance interface{}, xerr *Error) {
+func (c *client) getBalance(ctx context.Context, addr string) (balance interface{}, xerr *Error) {
 	params := []interface{}{addr, "latest"}
-	reply, err := c.rpcCall(c.nameSpace+"_getBalance", params)
+	reply, err := c.rpcCall(ctx, &rpcRequest{method: c.nameSpace + "_getBalance", params: params})
 	if err != nil {
-		sdklog.Error("getBalance error.", "err", err)
+		c.log.Error("getBalance error.", "err", err)
 		return nil, ErrRpcGetBalance.Join(err)
 	}
 	var res rpcReply
 	json.Unmarshal(reply, &res)
-	sdklog.Info("getBalance.", "params", params, "reply", string(reply))
+	c.log.Info("getBalance.", "params", params, "reply", Payload(reply))
 	if res.Result != nil {
 		var suc bool
 		balance, suc = new(big.Int).SetString(res.Result.(string), 0)
@@ -127,16 +166,16 @@ func (c *client) getBalance(addr string) (balance interface{}, xerr *Error) {
 	return
 }
 
-func (c *client) getGasPrice() (gasPrice *big.Int, xerr *Error) {
+func (c *client) getGasPrice(ctx context.Context) (gasPrice *big.Int, xerr *Error) {
 	params := []interface{}{}
-	reply, err := c.rpcCall(c.nameSpace+"_gasPrice", params)
+	reply, err := c.rpcCall(ctx, &rpcRequest{method: c.nameSpace + "_gasPrice", params: params})
 	if err != nil {
-		sdklog.Error("gasPrice error.", "err", err)
+		c.log.Error("gasPrice error.", "err", err)
 		return nil, ErrRpcGetGasPrice.Join(err)
 	}
 	var res rpcReply
 	json.Unmarshal(reply, &res)
-	sdklog.Info("gasPrice.", "params", params, "reply", string(reply))
+	c.log.Info("gasPrice.", "params", params, "reply", Payload(reply))
 	if res.Result != nil {
 		if ret, ok := res.Result.(float64); ok {
 			gasPrice = new(big.Int).SetInt64(int64(ret))
@@ -149,12 +188,12 @@ func (c *client) getGasPrice() (gasPrice *big.Int, xerr *Error) {
 		}
 		return gasPrice, nil
 	}
-	sdklog.Error("res.Result nil")
+	c.log.Error("res.Result nil")
 	xerr = &res.Err
 	return
 }
 
-func (c *client) estimateGas(from, to, data string, value big.Int) (gas *big.Int, xerr *Error) {
+func (c *client) estimateGas(ctx context.Context, from, to, data string, value big.Int) (gas *big.Int, xerr *Error) {
 	params := []interface{}{
 		map[string]interface{}{
 			"from":  from,
@@ -164,14 +203,14 @@ func (c *client) estimateGas(from, to, data string, value big.Int) (gas *big.Int
 		},
 	}
 	authParams := []interface{}{from, to, data}
-	reply, err := c.rpcCallWithAuth(c.nameSpace+"_estimateGas", params, authParams)
+	reply, err := c.rpcCall(ctx, &rpcRequest{method: c.nameSpace + "_estimateGas", params: params, authParams: authParams})
 	if err != nil {
-		sdklog.Error("estimateGas error.", "err", err)
+		c.log.Error("estimateGas error.", "err", err)
 		return new(big.Int), ErrRpcEstimateGas.Join(err)
 	}
 	var res rpcReply
 	json.Unmarshal(reply, &res)
-	sdklog.Info("estimateGas.", "params", params, "reply", string(reply))
+	c.log.Info("estimateGas.", "params", params, "reply", Payload(reply))
 	if res.Result != nil {
 		gasRes, ok := new(big.Int).SetString(res.Result.(string), 0)
 		if !ok {
@@ -183,85 +222,95 @@ func (c *client) estimateGas(from, to, data string, value big.Int) (gas *big.Int
 	return
 }
 
-func (c *client) getTransactionByHash(from, hash string) (receipt interface{}, xerr *Error) {
+func (c *client) getTransactionByHash(ctx context.Context, from, hash string) (receipt interface{}, xerr *Error) {
 	params := []interface{}{hash}
-	reply, err := c.rpcCallWithFrom(c.nameSpace+"_getTransactionByHash", params, from)
+	reply, err := c.rpcCall(ctx, &rpcRequest{method: c.nameSpace + "_getTransactionByHash", params: params, from: from})
 	if err != nil {
-		sdklog.Error("getTransactionByHash error.", "err", err)
+		c.log.Error("getTransactionByHash error.", "err", err)
 		return nil, ErrRpcGetTransactionByHash.Join(err)
 	}
 	var res rpcReply
 	json.Unmarshal(reply, &res)
-	sdklog.Info("getTransactionByHash.", "params", params, "result", res)
+	c.log.Info("getTransactionByHash.", "params", params, "result", res)
 	return res.Result, &res.Err
 }
 
-func (c *client) getTransactionReceipt(hash string) (receipt interface{}, xerr *Error) {
+func (c *client) getTransactionReceipt(ctx context.Context, hash string) (receipt interface{}, xerr *Error) {
 	params := []interface{}{hash}
-	reply, err := c.rpcCall(c.nameSpace+"_getTransactionReceipt", params)
+	reply, err := c.rpcCall(ctx, &rpcRequest{method: c.nameSpace + "_getTransactionReceipt", params: params})
 	if err != nil {
-		sdklog.Error("getTransactionReceipt error.", "err", err)
+		c.log.Error("getTransactionReceipt error.", "err", err)
 		return nil, ErrRpcGetTransactionReceipt.Join(err)
 	}
 	var res rpcReply
 	json.Unmarshal(reply, &res)
-	sdklog.Info("getTransactionReceipt.", "params", params, "result", res)
+	c.log.Info("getTransactionReceipt.", "params", params, "result", res)
 	return res.Result, &res.Err
 }
 
-func (c *client) getBlockByHash(hash string, fullTxReturn bool) (receipt interface{}, xerr *Error) {
+func (c *client) getBlockByHash(ctx context.Context, hash string, fullTxReturn bool) (receipt interface{}, xerr *Error) {
 	params := []interface{}{hash, strconv.FormatBool(fullTxReturn)}
-	reply, err := c.rpcCall(c.nameSpace+"_getBlockByHash", params)
+	reply, err := c.rpcCall(ctx, &rpcRequest{method: c.nameSpace + "_getBlockByHash", params: params})
 	if err != nil {
-		sdklog.Error("getBlockByHash error.", "err", err)
+		c.log.Error("getBlockByHash error.", "err", err)
 		return nil, ErrRpcgetBlockByHash.Join(err)
 	}
 	var res rpcReply
 	json.Unmarshal(reply, &res)
-	sdklog.Info("getBlockByHash.", "params", params, "result", res)
+	c.log.Info("getBlockByHash.", "params", params, "result", res)
 	return res.Result, &res.Err
 }
 
-func (c *client) getBlockByNumber(number string, fullTxReturn bool) (receipt interface{}, xerr *Error) {
+func (c *client) getBlockByNumber(ctx context.Context, number string, fullTxReturn bool) (receipt interface{}, xerr *Error) {
 	params := []interface{}{number, fullTxReturn}
-	reply, err := c.rpcCall(c.nameSpace+"_getBlockByNumber", params)
+	reply, err := c.rpcCall(ctx, &rpcRequest{method: c.nameSpace + "_getBlockByNumber", params: params})
 	if err != nil {
-		sdklog.Error("getBlockByNumber error.", "err", err)
+		c.log.Error("getBlockByNumber error.", "err", err)
 		return nil, ErrRpcgetBlockByNumber.Join(err)
 	}
 	var res rpcReply
 	json.Unmarshal(reply, &res)
-	sdklog.Info("getBlockByNumber.", "params", params, "result", res)
+	c.log.Info("getBlockByNumber.", "params", params, "result", res)
 	return res.Result, &res.Err
 }
 
-func (c *client) sendTransaction(raw string) (interface{}, *Error) {
+func (c *client) sendTransaction(ctx context.Context, raw string) (interface{}, *Error) {
 	params := []interface{}{raw}
-	reply, err := c.rpcCall(c.nameSpace+"_sendRawTransaction", params)
+	reply, err := c.rpcCall(ctx, &rpcRequest{method: c.nameSpace + "_sendRawTransaction", params: params})
 	if err != nil {
-		sdklog.Error("sendRawTransaction error.", "err", err)
+		c.log.Error("sendRawTransaction error.", "err", err)
 		return "", ErrRpcSendTransaction.Join(err)
 	}
 	var res rpcReply
 	json.Unmarshal(reply, &res)
-	sdklog.Info("sendRawTransaction", "raw", raw, "reply", string(reply))
+	c.log.Info("sendRawTransaction", "raw", Payload(raw), "reply", Payload(reply))
+	c.sent(res.Result, &res.Err)
 	return res.Result, &res.Err
 }
 
-func (c *client) sendContractTransaction(raw string, ext interface{}) (interface{}, *Error) {
+func (c *client) sendContractTransaction(ctx context.Context, raw string, ext interface{}) (interface{}, *Error) {
 	params := []interface{}{raw}
-	reply, err := c.rpcCallWithExtension(c.nameSpace+"_sendRawTransaction", params, ext)
+	reply, err := c.rpcCall(ctx, &rpcRequest{method: c.nameSpace + "_sendRawTransaction", params: params, ext: ext})
 	if err != nil {
-		sdklog.Error("sendContractTransaction error.", "err", err)
+		c.log.Error("sendContractTransaction error.", "err", err)
 		return "", ErrRpcSendContractTransaction.Join(err)
 	}
 	var res rpcReply
 	json.Unmarshal(reply, &res)
-	sdklog.Info("sendContractTransaction.", "raw", raw, "ext", ext, "reply", string(reply))
+	extData, _ := json.Marshal(ext)
+	c.log.Info("sendContractTransaction.", "raw", Payload(raw), "ext", Payload(extData), "reply", Payload(reply))
+	c.sent(res.Result, &res.Err)
 	return res.Result, &res.Err
 }
 
-func (c *client) call(from, to, payload string) (interface{}, *Error) {
+// sent reports the hash of a transaction BaaS accepted to onSent
+func (c *client) sent(result interface{}, xerr *Error) {
+	if hash, ok := result.(string); ok && xerr.Code == 0 && c.onSent != nil {
+		c.onSent(common.HexToHash(hash))
+	}
+}
+
+func (c *client) call(ctx context.Context, from, to, payload string) (interface{}, *Error) {
 	params := []interface{}{
 		map[string]string{
 			"from": from,
@@ -271,7 +320,7 @@ func (c *client) call(from, to, payload string) (interface{}, *Error) {
 		"latest",
 	}
 	authParams := []interface{}{from, to, payload}
-	reply, err := c.rpcCallWithAuth(c.nameSpace+"_call", params, authParams)
+	reply, err := c.rpcCall(ctx, &rpcRequest{method: c.nameSpace + "_call", params: params, authParams: authParams})
 	if err != nil {
 		return "", ErrCall.Join(err)
 	}
@@ -281,93 +330,108 @@ func (c *client) call(from, to, payload string) (interface{}, *Error) {
 }
 
 // ------------------------------- inner call -------------------------------
-func (c *client) doRPCCallWithRetry(api string, from string, data []byte) (body []byte, err error) {
-	for cnt := 0; cnt < c.retry; cnt++ {
-		url := fmt.Sprintf("%s://%s/%s", c.protocal, c.xHost, api)
-		if len(from) != 0 {
-			url += fmt.Sprintf("?from=%s", from)
-		}
-		body, err = httpPostWithLongConn(url, c.xHost, "application/json", data)
-		if err != nil {
-			sdklog.Error("rpc call", "err", err)
-			continue
-		}
-		break
-	}
-	return
+// rpcRequest is a BaaS JSON-RPC call before it is encoded
+type rpcRequest struct {
+	method     string        // 带名称空间的方法 如 tcapi_getBalance
+	params     interface{}   // JSON-RPC params
+	authParams []interface{} // 参与auth签名的参数 为空时使用params
+	ext        interface{}   // extension字段 为空不填
+	from       string        // 查询参数from 为空不填
+}
+
+// rpcCall encodes req and sends it through the middleware chain with retry
+func (c *client) rpcCall(ctx context.Context, req *rpcRequest) (body []byte, err error) {
+	return c.doRPCCallWithRetry(ctx, req)
 }
 
-func (c *client) rpcCall(method string, params []interface{}) (body []byte, err error) {
+// encodeRPC marshals req with a fresh auth, v2 auth must not be resent as its nonce is single use
+func (c *client) encodeRPC(ctx context.Context, req *rpcRequest) ([]byte, error) {
 	rpcParams := make(map[string]interface{})
 	rpcParams["jsonrpc"] = "2.0"
-	rpcParams["method"] = method
-	rpcParams["params"] = params
+	rpcParams["method"] = req.method
+	rpcParams["params"] = req.params
 	rpcParams["id"] = 1
-	if auth := genRpcAuth(params, *c.auth); auth != nil {
-		rpcParams["auth"] = auth
+	if req.ext != nil {
+		rpcParams["extension"] = req.ext
 	}
-	data, err := json.Marshal(rpcParams)
+	auth, err := c.genAuth(ctx, req)
 	if err != nil {
 		return nil, err
 	}
-	strSlice := strings.Split(method, "_")
-	sdklog.Info("rpcCall", "data(params)", data)
-	return c.doRPCCallWithRetry(strSlice[1], "", data)
-}
-
-func (c *client) rpcCallWithExtension(method string, params []interface{}, ext interface{}) (body []byte, err error) {
-	rpcParams := make(map[string]interface{})
-	rpcParams["jsonrpc"] = "2.0"
-	rpcParams["method"] = method
-	rpcParams["params"] = params
-	rpcParams["extension"] = ext
-	rpcParams["id"] = 1
-	if auth := genRpcAuth(params, *c.auth); auth != nil {
+	if auth != nil {
 		rpcParams["auth"] = auth
 	}
 	data, err := json.Marshal(rpcParams)
 	if err != nil {
 		return nil, err
 	}
-	strSlice := strings.Split(method, "_")
-	sdklog.Info("rpcCallWithExtension", "data(params)", data)
-	return c.doRPCCallWithRetry(strSlice[1], "", data)
+	c.log.Debug("rpcCall", "data(params)", Payload(data))
+	return data, nil
 }
 
-func (c *client) rpcCallWithFrom(method string, params []interface{}, from string) (body []byte, err error) {
-	rpcParams := make(map[string]interface{})
-	rpcParams["jsonrpc"] = "2.0"
-	rpcParams["method"] = method
-	rpcParams["params"] = params
-	rpcParams["id"] = 1
-	if auth := genRpcAuth(params, *c.auth); auth != nil {
-		rpcParams["auth"] = auth
+func (c *client) doRPCCallWithRetry(ctx context.Context, req *rpcRequest) (body []byte, err error) {
+	method, from := req.method, req.from
+	api := method[strings.Index(method, "_")+1:]
+	m := &RPCMetric{
+		Method:   api,
+		Endpoint: fmt.Sprintf("%s://%s/%s", c.protocal, c.xHost, api),
+	}
+	ctx, span := startSpan(c.tracer, ctx, "rpc."+api)
+	span.SetAttributes("rpc.method", api, "rpc.endpoint", m.Endpoint)
+	start := time.Now()
+	for cnt := 0; cnt < c.retry; cnt++ {
+		url := m.Endpoint
+		if len(from) != 0 {
+			url += fmt.Sprintf("?from=%s", from)
+		}
+		var data []byte
+		if data, err = c.encodeRPC(ctx, req); err != nil {
+			break
+		}
+		m.Attempts++
+		var resp *Response
+		resp, err = c.transport.RoundTrip(&Request{
+			Context: ctx,
+			Method:  method,
+			API:     api,
+			URL:     url,
+			Header:  make(http.Header),
+			Body:    data,
+		})
+		if resp != nil {
+			body, m.Status = resp.Body, resp.StatusCode
+		}
+		if err != nil {
+			c.log.Error("rpc call", "err", err)
+			continue
+		}
+		break
 	}
-	data, err := json.Marshal(rpcParams)
-	if err != nil {
-		return nil, err
+	m.Duration = time.Since(start)
+	m.Err = err
+	if err == nil {
+		m.Code = replyCode(body)
 	}
-	strSlice := strings.Split(method, "_")
-	sdklog.Info("rpcCallWithFrom", "data(params)", data)
-	return c.doRPCCallWithRetry(strSlice[1], from, data)
+	c.metrics.ObserveRPC(m)
+	span.SetAttributes("rpc.attempts", m.Attempts, "http.status_code", m.Status, "rpc.code", m.Code)
+	span.end(err)
+	return
 }
 
-func (c *client) rpcCallWithAuth(method string, params interface{}, authParams []interface{}) (body []byte, err error) {
-	rpcParams := make(map[string]interface{})
-	rpcParams["jsonrpc"] = "2.0"
-	rpcParams["method"] = method
-	rpcParams["params"] = params
-	rpcParams["id"] = 1
-	if auth := genRpcAuth(authParams, *c.auth); auth != nil {
-		rpcParams["auth"] = auth
+// replyCode returns the BaaS error code in reply, both the JSON-RPC error
+// and the code field of getBaasSdkConf are checked.
+func replyCode(reply []byte) int {
+	var res struct {
+		Err  *Error `json:"error"`
+		Code int    `json:"code"`
 	}
-	data, err := json.Marshal(rpcParams)
-	if err != nil {
-		return nil, err
+	if err := json.Unmarshal(reply, &res); err != nil {
+		return 0
+	}
+	if res.Err != nil && res.Err.Code != 0 {
+		return res.Err.Code
 	}
-	strSlice := strings.Split(method, "_")
-	sdklog.Info("rpcCallWithAuth", "data(params)", data)
-	return c.doRPCCallWithRetry(strSlice[1], "", data)
+	return res.Code
 }
 
 // ------------------------------ getChainID ------------------------------
@@ -381,9 +445,9 @@ type ChainIDReply struct {
 	Data ChainIDData `json:"data"`
 }
 
-func (c *client) getChainID() (int64, error) {
+func (c *client) getChainID(ctx context.Context) (int64, error) {
 	params := []interface{}{}
-	reply, err := c.rpcCall(c.nameSpace+"_getBaasSdkConf", params)
+	reply, err := c.rpcCall(ctx, &rpcRequest{method: c.nameSpace + "_getBaasSdkConf", params: params})
 	if err != nil {
 		return 0, err
 	}
@@ -394,37 +458,3 @@ func (c *client) getChainID() (int64, error) {
 	}
 	return res.Data.ChainID, nil
 }
-
-// ------------------------------- auth -------------------------------
-type rpcAuth struct {
-	ChainID string `json:"chainid"`
-	ID      string `json:"sdkid"`
-	Rand    string `json:"rand"`
-	Sign    string `json:"sign"`
-}
-
-func genRpcAuth(params []interface{}, authInfo AuthInfo) *rpcAuth {
-	if authInfo.ChainID == "" || authInfo.ID == "" || authInfo.Key == "" {
-		return nil
-	}
-	rand := getRandString(16)
-	str := rand
-	for _, v := range params {
-		switch v.(type) {
-		case string:
-			str = str + strAND(v.(string))
-		case bool:
-			str = str + strAND(strconv.FormatBool(v.(bool)))
-		}
-	}
-	str = str + strAND(authInfo.ChainID) + strAND(authInfo.ID) + strAND(authInfo.Key)
-	hash := sha256.Sum256([]byte(str))
-	md5sum := fmt.Sprintf("%x", md5.Sum([]byte(fmt.Sprintf("%x", hash[:]))))
-	sdklog.Info("rpc-auth", "origin", str, "hash", hash, "sign", md5sum)
-	return &rpcAuth{
-		ChainID: authInfo.ChainID,
-		ID:      authInfo.ID,
-		Rand:    rand,
-		Sign:    md5sum,
-	}
-}
@@ -1,19 +1,39 @@
 package sdk
 
+import "time"
+
 type AuthInfo struct {
-	ChainID string `json:"chainid"` // 链ID
-	ID      string `json:"id"`      // BaaS为开发者分配的ID
-	Key     string `json:"key"`     // BaaS为开发者分配的Key
+	ChainID string    `json:"chainid"`        // 链ID
+	ID      string    `json:"id"`             // BaaS为开发者分配的ID
+	Key     string    `json:"key"`            // BaaS为开发者分配的Key
+	Keys    []AuthKey `json:"keys,omitempty"` // 轮换中的ID/Key 按生效时间选用 见 AuthInfo.Active
 }
 
 type Config struct {
-	Keystore       string            // Keystore目录 保存用户账户秘钥
-	UnlockAccounts map[string]string // 预解锁账户 从passwd.json中解析得到
-	Retry          int               // 请求失败的至多重复次数
-	RPCProtocal    string            // BaaS接入层 协议
-	XHost          string            // BaaS接入层 Host
-	Namespace      string            // 区块链名称空间 tcapi
-	ChainID        int64             // 链ID
-	GetGasPrice    bool              // 是否从BaaS获取GasPrice
-	AuthInfo       AuthInfo          // 与BaaS通信凭证 从auth.json中解析得到
+	Keystore       string             // Keystore目录 保存用户账户秘钥
+	UnlockAccounts map[string]string  // 预解锁账户 从passwd.json中解析得到
+	Retry          int                // 请求失败的至多重复次数
+	RPCProtocal    string             // BaaS接入层 协议
+	XHost          string             // BaaS接入层 Host
+	Namespace      string             // 区块链名称空间 tcapi
+	ChainID        int64              // 链ID
+	GetGasPrice    bool               // 是否从BaaS获取GasPrice
+	AuthInfo       AuthInfo           // 与BaaS通信凭证 从auth.json中解析得到
+	AuthScheme     string             // 请求鉴权方案 v1(默认 兼容旧版) 或 v2(HMAC-SHA256 含时间戳)
+	Credentials    CredentialProvider // 通信凭证来源 支持不重启轮换 为空时使用AuthInfo
+	CryptoType     string             // 新建账户的秘钥类型 secp256k1(默认) 或 gm(SM2)
+	SignHash       string             // 交易签名哈希算法 keccak256(默认) 或 sm3
+	Mnemonic       string             // HD钱包助记词(BIP-39) 为空则需通过ImportMnemonic导入
+	MnemonicPasswd string             // HD钱包助记词密码(BIP-39 passphrase) 可为空
+	HDPath         string             // HD钱包派生基础路径 默认 m/44'/60'/0'/0
+	UnlockTimeout  time.Duration      // 新建/导入账户的自动解锁时长 0表示永久解锁
+	PayloadLog     string             // 请求/响应内容日志级别 redacted(默认 敏感字段打码) none full(仅调试)
+	Metrics        Metrics            // RPC调用与签名的指标采集钩子 为空不采集
+	Tracer         Tracer             // 链路追踪钩子 为空时仅透传 traceparent
+	Middlewares    []Middleware       // BaaS请求中间件 按顺序由外向内包裹 每次重试均经过
+	Transport      RoundTripper       // 发送BaaS请求 为空时通过HTTP发送 测试时可替换为模拟链
+	PollInterval   time.Duration      // 订阅轮询BaaS的间隔 默认2s
+	Journal        Journal            // 交易日志 广播前记录已签名交易 启动时重新广播未确认的交易 为空不记录
+	Idempotency    IdempotencyStore   // 幂等键到首笔交易哈希的映射 为空时使用进程内存
+	IdempotencyTTL time.Duration      // 幂等键保留时长 默认24h
 }
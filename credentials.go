@@ -0,0 +1,302 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// AuthKey is one ID/Key pair of AuthInfo.Keys with its validity window
+type AuthKey struct {
+	ID        string    `json:"id"`
+	Key       string    `json:"key"`
+	NotBefore time.Time `json:"notbefore,omitempty"` // 生效时间 为空立即生效
+	NotAfter  time.Time `json:"notafter,omitempty"`  // 失效时间 为空永久有效
+}
+
+func (k *AuthKey) validAt(now time.Time) bool {
+	return !now.Before(k.NotBefore) && (k.NotAfter.IsZero() || now.Before(k.NotAfter))
+}
+
+// Active returns a with ID/Key replaced by the valid key of Keys that took effect
+// last, so that requests switch to a new key once its NotBefore is reached while
+// the old key stays accepted by BaaS until its NotAfter.
+func (a AuthInfo) Active(now time.Time) AuthInfo {
+	var best *AuthKey
+	for i := range a.Keys {
+		k := &a.Keys[i]
+		if k.validAt(now) && (best == nil || k.NotBefore.After(best.NotBefore)) {
+			best = k
+		}
+	}
+	res := AuthInfo{ChainID: a.ChainID, ID: a.ID, Key: a.Key}
+	if best != nil {
+		res.ID, res.Key = best.ID, best.Key
+	}
+	return res
+}
+
+// AcceptedKeys returns all keys of id valid at now, for verifying requests during rotation
+func (a AuthInfo) AcceptedKeys(id string, now time.Time) []string {
+	var keys []string
+	if a.ID == id && a.Key != "" {
+		keys = append(keys, a.Key)
+	}
+	for i := range a.Keys {
+		if k := &a.Keys[i]; k.ID == id && k.validAt(now) {
+			keys = append(keys, k.Key)
+		}
+	}
+	return keys
+}
+
+func (a AuthInfo) complete() bool {
+	return a.ChainID != "" && a.ID != "" && a.Key != ""
+}
+
+// CredentialProvider supplies the BaaS credentials, it is asked on every request
+// so rotated credentials take effect without restarting.
+type CredentialProvider interface {
+	Credentials(ctx context.Context) (AuthInfo, error)
+}
+
+// StaticCredentials is a CredentialProvider of fixed credentials
+type StaticCredentials AuthInfo
+
+// Credentials implements CredentialProvider
+func (s StaticCredentials) Credentials(ctx context.Context) (AuthInfo, error) {
+	return AuthInfo(s), nil
+}
+
+// EnvCredentials reads the credentials from the environment variables
+// <Prefix>CHAINID, <Prefix>ID and <Prefix>KEY on every request.
+type EnvCredentials struct {
+	Prefix string // 如 BAAS_
+}
+
+// Credentials implements CredentialProvider
+func (e EnvCredentials) Credentials(ctx context.Context) (AuthInfo, error) {
+	a := AuthInfo{
+		ChainID: os.Getenv(e.Prefix + "CHAINID"),
+		ID:      os.Getenv(e.Prefix + "ID"),
+		Key:     os.Getenv(e.Prefix + "KEY"),
+	}
+	if !a.complete() {
+		return a, fmt.Errorf("credentials: %sCHAINID, %sID or %sKEY not set", e.Prefix, e.Prefix, e.Prefix)
+	}
+	return a, nil
+}
+
+// FileCredentials reads the credentials from an auth.json file and reloads it when it changes
+type FileCredentials struct {
+	path string
+	log  Logger
+
+	mu      sync.RWMutex
+	auth    AuthInfo
+	modTime time.Time
+
+	quit chan struct{}
+	once sync.Once
+}
+
+// NewFileCredentials loads path and checks it for changes every interval, a file
+// that fails to load keeps the previous credentials. Close stops the checking.
+func NewFileCredentials(path string, interval time.Duration, log Logger) (*FileCredentials, error) {
+	if log == nil {
+		log = NopLogger()
+	}
+	f := &FileCredentials{path: path, log: log, quit: make(chan struct{})}
+	if err := f.reload(); err != nil {
+		return nil, err
+	}
+	if interval > 0 {
+		go f.loop(interval)
+	}
+	return f, nil
+}
+
+// Credentials implements CredentialProvider
+func (f *FileCredentials) Credentials(ctx context.Context) (AuthInfo, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.auth, nil
+}
+
+// Close stops checking the file for changes
+func (f *FileCredentials) Close() {
+	f.once.Do(func() { close(f.quit) })
+}
+
+func (f *FileCredentials) loop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := f.reload(); err != nil {
+				f.log.Error("FileCredentials reload fail", "path", f.path, "err", err)
+			}
+		case <-f.quit:
+			return
+		}
+	}
+}
+
+func (f *FileCredentials) reload() error {
+	fi, err := os.Stat(f.path)
+	if err != nil {
+		return err
+	}
+	f.mu.RLock()
+	unchanged := fi.ModTime().Equal(f.modTime)
+	f.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+	data, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return err
+	}
+	auth, err := parseAuthInfo(data)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	f.auth, f.modTime = auth, fi.ModTime()
+	f.mu.Unlock()
+	f.log.Info("FileCredentials loaded", "path", f.path, "id", auth.Active(time.Now()).ID)
+	return nil
+}
+
+// HTTPCredentials fetches the credentials as auth.json from a local secret store and
+// caches them for TTL. The cached credentials are served while a refresh runs in
+// the background; after a failed refresh they keep being served and the refresh is
+// retried with exponential backoff.
+type HTTPCredentials struct {
+	URL    string
+	TTL    time.Duration
+	Header http.Header // 访问密钥服务的请求头 如令牌
+	Client *http.Client
+
+	mu       sync.Mutex
+	auth     AuthInfo
+	expires  time.Time
+	err      error         // 最近一次获取失败的原因
+	retry    time.Time     // 获取失败后 此前不再获取
+	backoff  time.Duration // 连续失败的重试间隔
+	fetching chan struct{} // 进行中的获取 结束时关闭
+}
+
+const (
+	credentialsFetchTimeout = 10 * time.Second
+	credentialsMaxBackoff   = time.Minute
+)
+
+// Credentials implements CredentialProvider
+func (h *HTTPCredentials) Credentials(ctx context.Context) (AuthInfo, error) {
+	h.mu.Lock()
+	now := time.Now()
+	cached := h.auth.complete()
+	switch {
+	case cached && now.Before(h.expires):
+		defer h.mu.Unlock()
+		return h.auth, nil
+	case now.Before(h.retry):
+		defer h.mu.Unlock()
+		if cached {
+			return h.auth, nil
+		}
+		return AuthInfo{}, h.err
+	}
+	done := h.fetching
+	if done == nil {
+		done = make(chan struct{})
+		h.fetching = done
+		go h.refresh(done)
+	}
+	auth := h.auth
+	h.mu.Unlock()
+	if cached {
+		return auth, nil
+	}
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return AuthInfo{}, ctx.Err()
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.auth.complete() {
+		return h.auth, nil
+	}
+	return AuthInfo{}, h.err
+}
+
+// refresh fetches the credentials once for all callers and closes done. The fetch
+// is not bound to the context of the caller that started it.
+func (h *HTTPCredentials) refresh(done chan struct{}) {
+	ctx, cancel := context.WithTimeout(context.Background(), credentialsFetchTimeout)
+	auth, err := h.fetch(ctx)
+	cancel()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	defer close(done)
+	h.fetching = nil
+	if err != nil {
+		if h.backoff *= 2; h.backoff == 0 {
+			h.backoff = time.Second
+		}
+		if h.backoff > credentialsMaxBackoff {
+			h.backoff = credentialsMaxBackoff
+		}
+		h.err, h.retry = err, time.Now().Add(h.backoff)
+		return
+	}
+	h.auth, h.expires = auth, time.Now().Add(h.TTL)
+	h.err, h.retry, h.backoff = nil, time.Time{}, 0
+}
+
+func (h *HTTPCredentials) fetch(ctx context.Context) (AuthInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", h.URL, nil)
+	if err != nil {
+		return AuthInfo{}, err
+	}
+	for k, v := range h.Header {
+		req.Header[k] = v
+	}
+	cli := h.Client
+	if cli == nil {
+		cli = gHTTPClient
+	}
+	resp, err := cli.Do(req)
+	if err != nil {
+		return AuthInfo{}, fmt.Errorf("credentials: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return AuthInfo{}, fmt.Errorf("credentials: status code %d", resp.StatusCode)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return AuthInfo{}, err
+	}
+	return parseAuthInfo(data)
+}
+
+func parseAuthInfo(data []byte) (AuthInfo, error) {
+	var auth AuthInfo
+	if err := json.Unmarshal(data, &auth); err != nil {
+		return auth, err
+	}
+	if !auth.Active(time.Now()).complete() {
+		return auth, errors.New("credentials: chainid, id or key empty")
+	}
+	return auth, nil
+}
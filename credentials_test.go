@@ -0,0 +1,138 @@
+package sdk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// credentialsServer serves auth.json, fail makes it answer 500
+type credentialsServer struct {
+	*httptest.Server
+	hits    int32
+	fail    int32
+	release chan struct{} // 非nil时 请求阻塞至关闭
+}
+
+func newCredentialsServer(release chan struct{}) *credentialsServer {
+	s := &credentialsServer{release: release}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&s.hits, 1)
+		if s.release != nil {
+			<-s.release
+		}
+		if atomic.LoadInt32(&s.fail) != 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"chainid":"30261","id":"sdk-1","key":"secret-key"}`))
+	}))
+	return s
+}
+
+// waitRefreshed waits for the background refresh of h to end
+func waitRefreshed(t *testing.T, h *HTTPCredentials) {
+	t.Helper()
+	for i := 0; i < 200; i++ {
+		h.mu.Lock()
+		fetching := h.fetching
+		h.mu.Unlock()
+		if fetching == nil {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("refresh did not end")
+}
+
+func TestHTTPCredentialsSharedFetch(t *testing.T) {
+	release := make(chan struct{})
+	srv := newCredentialsServer(release)
+	defer srv.Close()
+	h := &HTTPCredentials{URL: srv.URL, TTL: time.Minute}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			auth, err := h.Credentials(context.Background())
+			if err == nil && auth.ID != "sdk-1" {
+				t.Errorf("id = %s", auth.ID)
+			}
+			errs <- err
+		}()
+	}
+	// a caller giving up does not cancel the shared fetch
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	if _, err := h.Credentials(ctx); err != context.DeadlineExceeded {
+		t.Errorf("canceled caller: err = %v", err)
+	}
+	cancel()
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	if hits := atomic.LoadInt32(&srv.hits); hits != 1 {
+		t.Errorf("fetched %d times, want 1", hits)
+	}
+}
+
+func TestHTTPCredentialsBackoff(t *testing.T) {
+	srv := newCredentialsServer(nil)
+	defer srv.Close()
+	h := &HTTPCredentials{URL: srv.URL, TTL: time.Millisecond}
+	if _, err := h.Credentials(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	atomic.StoreInt32(&srv.fail, 1)
+	time.Sleep(5 * time.Millisecond)
+	for i := 0; i < 20; i++ {
+		auth, err := h.Credentials(context.Background())
+		if err != nil || auth.ID != "sdk-1" {
+			t.Fatalf("cached credentials not served: %v %v", auth, err)
+		}
+		waitRefreshed(t, h)
+	}
+	if hits := atomic.LoadInt32(&srv.hits); hits != 2 {
+		t.Errorf("fetched %d times while down, want 1 failed fetch then backoff", hits-1)
+	}
+
+	// the endpoint recovers after the backoff
+	atomic.StoreInt32(&srv.fail, 0)
+	h.mu.Lock()
+	h.retry = time.Time{}
+	h.mu.Unlock()
+	h.Credentials(context.Background())
+	waitRefreshed(t, h)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.err != nil || h.backoff != 0 || !time.Now().Before(h.expires.Add(time.Second)) {
+		t.Errorf("not refreshed after recovery: err %v backoff %v", h.err, h.backoff)
+	}
+}
+
+func TestHTTPCredentialsNoCache(t *testing.T) {
+	srv := newCredentialsServer(nil)
+	defer srv.Close()
+	atomic.StoreInt32(&srv.fail, 1)
+	h := &HTTPCredentials{URL: srv.URL, TTL: time.Minute}
+	for i := 0; i < 5; i++ {
+		if _, err := h.Credentials(context.Background()); err == nil {
+			t.Fatal("credentials without a successful fetch")
+		}
+	}
+	if hits := atomic.LoadInt32(&srv.hits); hits != 1 {
+		t.Errorf("fetched %d times within the backoff, want 1", hits)
+	}
+}
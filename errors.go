@@ -142,4 +142,54 @@ var (
 		Code: -1029,
 		Msg:  "SendRawTransaction error",
 	}
+
+	ErrHDWallet = &Error{
+		Code: -1030,
+		Msg:  "hd wallet err",
+	}
+
+	ErrImportAccount = &Error{
+		Code: -1031,
+		Msg:  "import account err",
+	}
+
+	ErrExportAccount = &Error{
+		Code: -1032,
+		Msg:  "export account err",
+	}
+
+	ErrUpdatePassword = &Error{
+		Code: -1033,
+		Msg:  "update password err",
+	}
+
+	ErrDeleteAccount = &Error{
+		Code: -1034,
+		Msg:  "delete account err",
+	}
+
+	ErrUnlockAccount = &Error{
+		Code: -1035,
+		Msg:  "unlock account err",
+	}
+
+	ErrInternal = &Error{
+		Code: -1036,
+		Msg:  "internal err",
+	}
+
+	ErrSubscribe = &Error{
+		Code: -1037,
+		Msg:  "subscribe err",
+	}
+
+	ErrJournal = &Error{
+		Code: -1038,
+		Msg:  "journal err",
+	}
+
+	ErrIdempotency = &Error{
+		Code: -1039,
+		Msg:  "idempotency err",
+	}
 )
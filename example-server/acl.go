@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	sdk "github.com/XunleiBlockchain/baas-sdk-go"
+)
+
+// aclClient is one client of the ACL file with its credentials and permissions
+type aclClient struct {
+	Name          string   `json:"name"`
+	APIKeys       []string `json:"apikeys"`        // 静态API Key
+	HMACSecret    string   `json:"hmac_secret"`    // HMAC签名密钥 X-Client-Id为name
+	CertCN        string   `json:"cert_cn"`        // mTLS客户端证书CN
+	WebhookSecret string   `json:"webhook_secret"` // 异步任务webhook签名密钥 为空使用async.webhook.secret
+	Methods       []string `json:"methods"`        // 允许调用的方法 "*"表示全部
+	From          []string `json:"from"`           // 允许使用的账户地址 为空不限制
+}
+
+type aclConfig struct {
+	Clients []aclClient `json:"clients"`
+}
+
+type aclEntry struct {
+	allMethods bool
+	methods    map[string]bool
+	from       map[string]bool
+}
+
+// acl maps authenticated clients to their allowed methods and accounts
+type acl struct {
+	clients map[string]*aclEntry
+	apiKeys *apiKeyAuth
+	hmac    *hmacAuth
+	mtls    *mtlsAuth
+
+	webhookSecrets map[string]string // 客户端name到webhook签名密钥
+}
+
+// loadACL reads the ACL json file at path
+func loadACL(path string) (*acl, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg aclConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("acl %s: %v", path, err)
+	}
+	a := &acl{
+		clients: make(map[string]*aclEntry),
+		apiKeys: &apiKeyAuth{clients: make(map[[sha256.Size]byte]string)},
+		hmac:    &hmacAuth{secrets: make(map[string]string), maxSkew: sdk.DefaultAuthMaxSkew, nonces: make(map[string]time.Time)},
+		mtls:    &mtlsAuth{clients: make(map[string]string)},
+
+		webhookSecrets: make(map[string]string),
+	}
+	for _, c := range cfg.Clients {
+		if c.Name == "" {
+			return nil, fmt.Errorf("acl %s: client without name", path)
+		}
+		if _, ok := a.clients[c.Name]; ok {
+			return nil, fmt.Errorf("acl %s: duplicate client %s", path, c.Name)
+		}
+		entry := &aclEntry{methods: make(map[string]bool), from: make(map[string]bool)}
+		for _, m := range c.Methods {
+			if m == "*" {
+				entry.allMethods = true
+			}
+			entry.methods[m] = true
+		}
+		for _, addr := range c.From {
+			if !isHexAddress(addr) {
+				return nil, fmt.Errorf("acl %s: client %s: invalid from address %s", path, c.Name, addr)
+			}
+			entry.from[normalizeAddress(addr)] = true
+		}
+		a.clients[c.Name] = entry
+		for _, key := range c.APIKeys {
+			a.apiKeys.clients[sha256.Sum256([]byte(key))] = c.Name
+		}
+		if c.HMACSecret != "" {
+			a.hmac.secrets[c.Name] = c.HMACSecret
+		}
+		if c.CertCN != "" {
+			a.mtls.clients[c.CertCN] = c.Name
+		}
+		if c.WebhookSecret != "" {
+			a.webhookSecrets[c.Name] = c.WebhookSecret
+		}
+	}
+	return a, nil
+}
+
+// authenticator returns the authenticator of the comma separated schemes, e.g. "apikey,hmac"
+func (a *acl) authenticator(schemes string) (authenticator, error) {
+	var res multiAuth
+	for _, s := range strings.Split(schemes, ",") {
+		switch strings.TrimSpace(s) {
+		case authAPIKey:
+			res = append(res, a.apiKeys)
+		case authHMAC:
+			res = append(res, a.hmac)
+		case authMTLS:
+			res = append(res, a.mtls)
+		case "":
+		default:
+			return nil, fmt.Errorf("unknown http.auth scheme %s", s)
+		}
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("no http.auth scheme")
+	}
+	return res, nil
+}
+
+// middleware rejects calls of methods, or acting as accounts, the client is not allowed.
+// The accounts are the address params and the from field of object params named
+// in the method's schema. Clients restricted to accounts may call methods acting
+// on no account, e.g. ImportRawKey writing into the shared keystore, only if they
+// are listed by name, and never replace the shared HD seed with overwrite.
+func (a *acl) middleware(reg *registry) methodMiddleware {
+	return func(method string, next handler) handler {
+		return func(ctx context.Context, params interface{}) (interface{}, *sdk.Error) {
+			client, _ := clientFromContext(ctx)
+			entry, ok := a.clients[client]
+			if !ok {
+				return nil, errForbidden.Join(fmt.Errorf("unknown client %s", client))
+			}
+			if !entry.allMethods && !entry.methods[method] {
+				return nil, errForbidden.Join(fmt.Errorf("method %s not allowed", method))
+			}
+			if len(entry.from) != 0 {
+				schema := reg.methods[method].params
+				accounts := accountsOf(schema, params)
+				if len(accounts) == 0 && !entry.methods[method] {
+					return nil, errForbidden.Join(fmt.Errorf("method %s acts on no account, list it in methods to allow", method))
+				}
+				for _, addr := range accounts {
+					if !entry.from[normalizeAddress(addr)] {
+						return nil, errForbidden.Join(fmt.Errorf("account %s not allowed", addr))
+					}
+				}
+				if overwrites(schema, params) {
+					return nil, errForbidden.Join(fmt.Errorf("overwrite not allowed"))
+				}
+			}
+			return next(ctx, params)
+		}
+	}
+}
+
+// accountsOf returns the accounts params act as: the params named address or from,
+// and the from field of object params
+func accountsOf(schema []paramSchema, params interface{}) []string {
+	args, _ := params.([]interface{})
+	var res []string
+	for i, p := range schema {
+		if i >= len(args) {
+			break
+		}
+		switch {
+		case p.Type == typeAddress && (p.Name == "address" || p.Name == "from"):
+			if s, ok := args[i].(string); ok {
+				res = append(res, s)
+			}
+		case p.Type == typeObject:
+			if obj, ok := args[i].(map[string]interface{}); ok {
+				if s, ok := obj["from"].(string); ok {
+					res = append(res, s)
+				}
+			}
+		}
+	}
+	return res
+}
+
+// overwrites reports whether params set the overwrite param to anything but false
+func overwrites(schema []paramSchema, params interface{}) bool {
+	args, _ := params.([]interface{})
+	for i, p := range schema {
+		if p.Name == "overwrite" && i < len(args) {
+			return args[i] != nil && args[i] != false && args[i] != "false"
+		}
+	}
+	return false
+}
+
+func normalizeAddress(addr string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(addr, "0x"), "0X"))
+}
+
+func isHexAddress(addr string) bool {
+	b, err := hex.DecodeString(normalizeAddress(addr))
+	return err == nil && len(b) == 20
+}
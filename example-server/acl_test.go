@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	sdk "github.com/XunleiBlockchain/baas-sdk-go"
+)
+
+const (
+	allowedFrom = "0x54fb1c7d0f011dd63b08f85ed7b518ab82028100"
+	otherFrom   = "0x33d4fcb75ce608920c7e5755304c282141dfc4dc"
+)
+
+func TestACLFromRestricted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "acl.json")
+	err := ioutil.WriteFile(path, []byte(`{"clients":[
+		{"name":"payments","methods":["*","blockNumber","importMnemonic"],"from":["`+allowedFrom+`"]},
+		{"name":"admin","methods":["*"]}
+	]}`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := loadACL(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg := &registry{methods: make(map[string]*method)}
+	for name, schema := range sdkSchemas {
+		reg.register(lowerFirst(name), schema, func(ctx context.Context, params interface{}) (interface{}, *sdk.Error) {
+			return true, nil
+		})
+	}
+	if err := reg.use(a.middleware(reg)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		client  string
+		method  string
+		params  []interface{}
+		allowed bool
+	}{
+		{"payments", "getBalance", []interface{}{allowedFrom}, true},
+		{"payments", "getBalance", []interface{}{otherFrom}, false},
+		{"payments", "sendTransaction", []interface{}{map[string]interface{}{"from": otherFrom}}, false},
+		{"payments", "blockNumber", nil, true},
+		// no account param and not listed by name
+		{"payments", "getTransactionReceipt", []interface{}{"0x01"}, false},
+		{"payments", "importRawKey", []interface{}{"0x01", "p"}, false},
+		{"payments", "importKeystore", []interface{}{map[string]interface{}{}, "p"}, false},
+		{"payments", "deriveAccount", []interface{}{"0x1", "p"}, false},
+		{"payments", "newMnemonic", nil, false},
+		// listed, but the shared seed is never replaced
+		{"payments", "importMnemonic", []interface{}{"m", ""}, true},
+		{"payments", "importMnemonic", []interface{}{"m", "", true}, false},
+		{"payments", "importMnemonic", []interface{}{"m", "", "true"}, false},
+		{"admin", "importRawKey", []interface{}{"0x01", "p"}, true},
+		{"admin", "newMnemonic", []interface{}{"0x80", true}, true},
+	} {
+		_, xerr := reg.call(withClient(context.Background(), c.client), c.method, c.params)
+		if allowed := xerr == nil || xerr.Code == 0; allowed != c.allowed {
+			t.Errorf("%s %s %v: allowed %v, want %v (%v)", c.client, c.method, c.params, allowed, c.allowed, xerr)
+		}
+	}
+}
@@ -0,0 +1,474 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	sdk "github.com/XunleiBlockchain/baas-sdk-go"
+)
+
+const (
+	jobQueued  = "queued"  // 等待签名发送 之后为 pending success failed dropped unknown
+	jobUnknown = "unknown" // 已发送但跟踪失败 结果未知 可按hash查询
+
+	asyncMaxJobs       = 1024      // 未完成的任务数上限
+	asyncJobTTL        = time.Hour // 完成的任务保留时间
+	webhookMaxBackoff  = time.Minute
+	headerJobID        = "X-Job-Id"
+	webhookContentType = "application/json"
+)
+
+var errAsync = &sdk.Error{Code: -2003, Msg: "async job err"}
+
+// asyncJob is an asynchronous submission, it is the webhook payload and the getJob result
+type asyncJob struct {
+	ID        string      `json:"id"`
+	Method    string      `json:"method"`
+	Status    string      `json:"status"`
+	Hash      string      `json:"hash,omitempty"`
+	Receipt   interface{} `json:"receipt,omitempty"`
+	Error     *sdk.Error  `json:"error,omitempty"`
+	Webhook   string      `json:"webhook"`
+	Attempts  int         `json:"attempts"`  // webhook投递次数
+	Delivered bool        `json:"delivered"` // webhook已收到2xx响应
+	Created   int64       `json:"created"`
+	Updated   int64       `json:"updated"`
+
+	client string
+	done   time.Time // 交易结束时间 未结束为零值
+}
+
+// asyncJobs signs, sends and tracks the async submissions in the background and
+// posts their outcome to the caller's webhook
+type asyncJobs struct {
+	mySDK   *sdk.SDKImpl
+	log     sdk.Logger
+	secret  string
+	retries int
+	allow   *webhookPolicy
+	client  *http.Client
+	secrets map[string]string // 客户端的webhook签名密钥 未配置的使用secret
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu   sync.Mutex
+	jobs map[string]*asyncJob
+}
+
+// newAsyncJobs signs webhooks with the secret of the job's client, or secret if the
+// client has none, a failed delivery is retried up to retries times. Webhooks may
+// only reach public addresses and the comma separated hosts and CIDRs of allow.
+func newAsyncJobs(mySDK *sdk.SDKImpl, secret, allow string, retries int, timeout time.Duration, log sdk.Logger) (*asyncJobs, error) {
+	policy, err := newWebhookPolicy(allow)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &asyncJobs{
+		mySDK:   mySDK,
+		log:     log,
+		secret:  secret,
+		retries: retries,
+		allow:   policy,
+		client: &http.Client{
+			Timeout: timeout,
+			// no proxy, the policy applies to the addresses dialed
+			Transport: &http.Transport{DialContext: policy.dialContext(&net.Dialer{Timeout: timeout})},
+		},
+		ctx:    ctx,
+		cancel: cancel,
+		jobs:   make(map[string]*asyncJob),
+	}, nil
+}
+
+// registerAsync registers prefix + sendTransactionAsync, sendContractTransactionAsync
+// and getJob. The async methods take the webhook url followed by the params of the
+// synchronous method, which is called without its middlewares once the async one
+// is allowed.
+func registerAsync(reg *registry, jobs *asyncJobs) {
+	for _, name := range []string{"SendTransaction", "SendContractTransaction"} {
+		m := reg.methods[reg.prefix+lowerFirst(name)]
+		params := append([]paramSchema{required("webhook", typeString)}, m.params...)
+		reg.register(reg.prefix+lowerFirst(name)+"Async", methodSchema{
+			desc:   "submits " + m.name + " in the background and returns the job id, the outcome is posted to the webhook",
+			params: params,
+		}, jobs.submit(m.name, m.h))
+	}
+	reg.register(reg.prefix+"getJob", methodSchema{
+		desc: "returns an async job of the client, null if unknown or expired",
+		params: []paramSchema{
+			required("id", typeString),
+		},
+	}, jobs.get)
+}
+
+func (j *asyncJobs) submit(method string, send handler) handler {
+	return func(ctx context.Context, params interface{}) (interface{}, *sdk.Error) {
+		args, _ := params.([]interface{})
+		if len(args) < 2 {
+			return nil, sdk.ErrParams.Join(errors.New("params: expected the webhook url and the params of " + method))
+		}
+		webhook, _ := args[0].(string)
+		if err := j.checkWebhook(ctx, webhook); err != nil {
+			return nil, sdk.ErrParams.Join(fmt.Errorf("params[0]: %v", err))
+		}
+		if _, ok := args[1].(map[string]interface{}); !ok {
+			return nil, sdk.ErrParams.Join(errors.New("params[1]: expected tx object"))
+		}
+		client, _ := clientFromContext(ctx)
+		if j.secretOf(client) == "" {
+			return nil, errAsync.Join(fmt.Errorf("no webhook secret for client %s", client))
+		}
+		job, err := j.add(method, webhook, client)
+		if err != nil {
+			return nil, errAsync.Join(err)
+		}
+		key, _ := sdk.IdempotencyKeyFromContext(ctx)
+		go j.run(job, send, args[1:], key)
+		return job.ID, nil
+	}
+}
+
+func (j *asyncJobs) get(ctx context.Context, params interface{}) (interface{}, *sdk.Error) {
+	args, _ := params.([]interface{})
+	if len(args) != 1 {
+		return nil, sdk.ErrParams.Join(errors.New("params: expected 1 element"))
+	}
+	id, ok := args[0].(string)
+	if !ok {
+		return nil, sdk.ErrParams.Join(errors.New("params[0]: expected job id string"))
+	}
+	client, _ := clientFromContext(ctx)
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	job, ok := j.jobs[id]
+	if !ok || job.client != client {
+		return nil, nil
+	}
+	res := *job
+	return &res, nil
+}
+
+// checkWebhook accepts absolute http and https urls whose host the policy allows
+func (j *asyncJobs) checkWebhook(ctx context.Context, webhook string) error {
+	u, err := url.Parse(webhook)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("webhook %q is not a http(s) url", webhook)
+	}
+	return j.allow.check(ctx, u.Hostname())
+}
+
+// deniedNets are not reachable by webhooks unless allowed, besides the loopback,
+// link-local and unspecified addresses
+var deniedNets = parseCIDRs("0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "172.16.0.0/12", "192.168.0.0/16", "198.18.0.0/15", "fc00::/7")
+
+func parseCIDRs(cidrs ...string) []*net.IPNet {
+	res := make([]*net.IPNet, len(cidrs))
+	for i, c := range cidrs {
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			panic(err)
+		}
+		res[i] = n
+	}
+	return res
+}
+
+// webhookPolicy restricts webhooks to public addresses, the allowed hosts and the
+// allowed networks
+type webhookPolicy struct {
+	hosts map[string]bool
+	nets  []*net.IPNet
+}
+
+// newWebhookPolicy parses a comma separated list of host names, IPs and CIDRs
+func newWebhookPolicy(allow string) (*webhookPolicy, error) {
+	p := &webhookPolicy{hosts: make(map[string]bool)}
+	for _, s := range strings.Split(allow, ",") {
+		s = strings.TrimSpace(s)
+		switch {
+		case s == "":
+		case strings.Contains(s, "/"):
+			_, n, err := net.ParseCIDR(s)
+			if err != nil {
+				return nil, fmt.Errorf("async.webhook.allow: %v", err)
+			}
+			p.nets = append(p.nets, n)
+		case net.ParseIP(s) != nil:
+			ip := net.ParseIP(s)
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			p.nets = append(p.nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+		default:
+			p.hosts[strings.ToLower(s)] = true
+		}
+	}
+	return p, nil
+}
+
+func (p *webhookPolicy) allowedIP(ip net.IP) bool {
+	for _, n := range p.nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsUnspecified() {
+		return false
+	}
+	for _, n := range deniedNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// check resolves host and rejects it if any of its addresses is not allowed
+func (p *webhookPolicy) check(ctx context.Context, host string) error {
+	if p.hosts[strings.ToLower(host)] {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		if !p.allowedIP(ip) {
+			return fmt.Errorf("webhook address %s not allowed", ip)
+		}
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if !p.allowedIP(addr.IP) {
+			return fmt.Errorf("webhook host %s resolves to %s, not allowed", host, addr.IP)
+		}
+	}
+	return nil
+}
+
+// dialContext checks the address actually dialed, which covers redirects and
+// host names resolving differently after checkWebhook
+func (p *webhookPolicy) dialContext(dialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	checked := *dialer
+	checked.Control = func(network, address string, c syscall.RawConn) error {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return err
+		}
+		if ip := net.ParseIP(host); ip == nil || !p.allowedIP(ip) {
+			return fmt.Errorf("webhook address %s not allowed", host)
+		}
+		return nil
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err == nil && p.hosts[strings.ToLower(host)] {
+			return dialer.DialContext(ctx, network, addr)
+		}
+		return checked.DialContext(ctx, network, addr)
+	}
+}
+
+// add creates a queued job to run, dropping the finished ones past asyncJobTTL
+func (j *asyncJobs) add(method, webhook, client string) (*asyncJob, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.ctx.Err() != nil {
+		return nil, errors.New("server shutting down")
+	}
+	active := 0
+	for jid, job := range j.jobs {
+		switch {
+		case job.done.IsZero():
+			active++
+		case now.Sub(job.done) > asyncJobTTL:
+			delete(j.jobs, jid)
+		}
+	}
+	if active >= asyncMaxJobs {
+		return nil, fmt.Errorf("at most %d unfinished jobs", asyncMaxJobs)
+	}
+	job := &asyncJob{
+		ID:      "0x" + hex.EncodeToString(id),
+		Method:  method,
+		Status:  jobQueued,
+		Webhook: webhook,
+		Created: now.Unix(),
+		Updated: now.Unix(),
+		client:  client,
+	}
+	j.jobs[job.ID] = job
+	j.wg.Add(1)
+	return job, nil
+}
+
+// update applies fn to job under the lock and returns the webhook payload
+func (j *asyncJobs) update(job *asyncJob, fn func(job *asyncJob)) []byte {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	fn(job)
+	job.Updated = time.Now().Unix()
+	payload, _ := json.Marshal(job)
+	return payload
+}
+
+// run sends the transaction under the idempotency key of the submission, waits
+// until it is mined or dropped and delivers the outcome. If the transaction can
+// not be tracked the job ends as unknown.
+func (j *asyncJobs) run(job *asyncJob, send handler, params []interface{}, idempotencyKey string) {
+	defer j.wg.Done()
+	// the send is not canceled on shutdown, its hash would be lost
+	ctx := withClient(context.Background(), job.client)
+	ctx = sdk.ContextWithIdempotencyKey(ctx, idempotencyKey)
+	ret, xerr := send(ctx, params)
+	if xerr != nil && xerr.Code != 0 {
+		j.log.Warn("async job send fail", "id", job.ID, "method", job.Method, "err", xerr)
+		j.finish(job, sdk.TxFailed, nil, xerr)
+		return
+	}
+	hash, _ := ret.(string)
+	j.update(job, func(job *asyncJob) {
+		job.Status = sdk.TxPending
+		job.Hash = hash
+	})
+	sub, xerr := j.mySDK.Subscribe([]interface{}{sdk.SubPendingTxStatus, map[string]interface{}{"hashes": []interface{}{hash}}})
+	if xerr != nil && xerr.Code != 0 {
+		j.log.Warn("async job subscribe fail", "id", job.ID, "hash", hash, "err", xerr)
+		j.finish(job, jobUnknown, nil, errAsync.Join(xerr))
+		return
+	}
+	defer sub.Unsubscribe()
+	for {
+		select {
+		case v, ok := <-sub.C():
+			if !ok {
+				err := sub.Err()
+				if err == nil {
+					err = errors.New("subscription ended")
+				}
+				j.log.Warn("async job tracking ended", "id", job.ID, "hash", hash, "err", err)
+				j.finish(job, jobUnknown, nil, errAsync.Join(err))
+				return
+			}
+			if st, ok := v.(*sdk.TxStatus); ok && st.Status != sdk.TxPending {
+				j.finish(job, st.Status, st.Receipt, nil)
+				return
+			}
+		case <-j.ctx.Done():
+			return
+		}
+	}
+}
+
+// finish records the final status of job and posts it to the webhook, retrying
+// with exponential backoff until a 2xx response, the retries are used up or shutdown
+func (j *asyncJobs) finish(job *asyncJob, status string, receipt interface{}, xerr *sdk.Error) {
+	j.update(job, func(job *asyncJob) {
+		job.Status = status
+		job.Receipt = receipt
+		job.Error = xerr
+		job.done = time.Now()
+	})
+	backoff := time.Second
+	for attempt := 0; attempt <= j.retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(backoff):
+			case <-j.ctx.Done():
+				return
+			}
+			if backoff *= 2; backoff > webhookMaxBackoff {
+				backoff = webhookMaxBackoff
+			}
+		}
+		payload := j.update(job, func(job *asyncJob) { job.Attempts++ })
+		err := j.post(job, payload)
+		if err == nil {
+			j.update(job, func(job *asyncJob) { job.Delivered = true })
+			return
+		}
+		j.log.Warn("async job webhook fail", "id", job.ID, "webhook", job.Webhook, "attempt", attempt+1, "err", err)
+	}
+}
+
+// post delivers payload signed with hex(HMAC-SHA256(secret, id\ntimestamp\nbody)) in X-Signature
+func (j *asyncJobs) post(job *asyncJob, payload []byte) error {
+	req, err := http.NewRequest(http.MethodPost, job.Webhook, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	ts := time.Now().Unix()
+	req = req.WithContext(j.ctx)
+	req.Header.Set("Content-Type", webhookContentType)
+	req.Header.Set(headerJobID, job.ID)
+	req.Header.Set(headerTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(headerSignature, signWebhook(j.secretOf(job.client), job.ID, ts, payload))
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("status %s", resp.Status)
+	}
+	return nil
+}
+
+// secretOf returns the webhook secret of client
+func (j *asyncJobs) secretOf(client string) string {
+	if secret, ok := j.secrets[client]; ok {
+		return secret
+	}
+	return j.secret
+}
+
+// signWebhook returns the X-Signature of a webhook payload
+func signWebhook(secret, id string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s\n%d\n", id, timestamp)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// close stops tracking and delivering, waiting for the jobs being sent until ctx is done
+func (j *asyncJobs) close(ctx context.Context) {
+	j.mu.Lock()
+	j.cancel()
+	j.mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		j.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
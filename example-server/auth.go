@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/XunleiBlockchain/baas-sdk-go"
+)
+
+// Client authentication schemes, see http.auth
+const (
+	authAPIKey = "apikey" // X-API-Key 或 Authorization: Bearer 请求头
+	authHMAC   = "hmac"   // X-Client-Id X-Timestamp X-Nonce X-Signature 请求头
+	authMTLS   = "mtls"   // 客户端证书CN 需开启TLS并校验客户端证书
+)
+
+// HMAC request headers
+const (
+	headerAPIKey    = "X-API-Key"
+	headerClientID  = "X-Client-Id"
+	headerTimestamp = "X-Timestamp"
+	headerNonce     = "X-Nonce"
+	headerSignature = "X-Signature"
+
+	headerIdempotencyKey = "Idempotency-Key"
+)
+
+var (
+	errUnauthorized = &sdk.Error{Code: -2001, Msg: "unauthorized"}
+	errForbidden    = &sdk.Error{Code: -2002, Msg: "forbidden"}
+)
+
+// errNoCredentials is returned by an authenticator for requests without its kind of credentials
+var errNoCredentials = errors.New("no credentials")
+
+// authenticator identifies the client of a request
+type authenticator interface {
+	authenticate(r *http.Request, body []byte) (client string, err error)
+}
+
+// multiAuth tries each authenticator in turn, the first one the request has
+// credentials for decides
+type multiAuth []authenticator
+
+func (m multiAuth) authenticate(r *http.Request, body []byte) (string, error) {
+	for _, a := range m {
+		client, err := a.authenticate(r, body)
+		if err != errNoCredentials {
+			return client, err
+		}
+	}
+	return "", errNoCredentials
+}
+
+// apiKeyAuth looks up static API keys by their SHA-256, so the lookup time does
+// not depend on the key
+type apiKeyAuth struct {
+	clients map[[sha256.Size]byte]string
+}
+
+func (a *apiKeyAuth) authenticate(r *http.Request, body []byte) (string, error) {
+	key := r.Header.Get(headerAPIKey)
+	if bearer := r.Header.Get("Authorization"); key == "" && strings.HasPrefix(bearer, "Bearer ") {
+		key = strings.TrimPrefix(bearer, "Bearer ")
+	}
+	if key == "" {
+		return "", errNoCredentials
+	}
+	client, ok := a.clients[sha256.Sum256([]byte(key))]
+	if !ok {
+		return "", errors.New("invalid api key")
+	}
+	return client, nil
+}
+
+// hmacAuth verifies hex(HMAC-SHA256(secret, clientID\ntimestamp\nnonce\nbody)) signed
+// requests, rejecting timestamps off by more than maxSkew and replayed nonces.
+type hmacAuth struct {
+	secrets map[string]string
+	maxSkew time.Duration
+
+	mu     sync.Mutex
+	nonces map[string]time.Time
+}
+
+func (a *hmacAuth) authenticate(r *http.Request, body []byte) (string, error) {
+	client := r.Header.Get(headerClientID)
+	sign := r.Header.Get(headerSignature)
+	if client == "" && sign == "" {
+		return "", errNoCredentials
+	}
+	secret, ok := a.secrets[client]
+	if !ok {
+		return "", fmt.Errorf("unknown client %s", client)
+	}
+	ts, err := strconv.ParseInt(r.Header.Get(headerTimestamp), 10, 64)
+	if err != nil {
+		return "", errors.New("invalid timestamp")
+	}
+	now := time.Now()
+	if t := time.Unix(ts, 0); t.Before(now.Add(-a.maxSkew)) || t.After(now.Add(a.maxSkew)) {
+		return "", fmt.Errorf("timestamp %d out of range", ts)
+	}
+	nonce := r.Header.Get(headerNonce)
+	if nonce == "" {
+		return "", errors.New("missing nonce")
+	}
+	expect := signRequest(secret, client, ts, nonce, body)
+	if !hmac.Equal([]byte(expect), []byte(strings.ToLower(sign))) {
+		return "", errors.New("invalid signature")
+	}
+	if err := a.checkNonce(client+"/"+nonce, now); err != nil {
+		return "", err
+	}
+	return client, nil
+}
+
+// signRequest returns the X-Signature of a request body
+func signRequest(secret, client string, timestamp int64, nonce string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s\n%d\n%s\n", client, timestamp, nonce)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// checkNonce rejects a nonce seen within the skew window
+func (a *hmacAuth) checkNonce(nonce string, now time.Time) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for n, t := range a.nonces {
+		if now.Sub(t) > 2*a.maxSkew {
+			delete(a.nonces, n)
+		}
+	}
+	if _, ok := a.nonces[nonce]; ok {
+		return errors.New("nonce replayed")
+	}
+	a.nonces[nonce] = now
+	return nil
+}
+
+// mtlsAuth identifies clients by the common name of their verified TLS certificate
+type mtlsAuth struct {
+	clients map[string]string
+}
+
+func (a *mtlsAuth) authenticate(r *http.Request, body []byte) (string, error) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+		return "", errNoCredentials
+	}
+	cn := r.TLS.VerifiedChains[0][0].Subject.CommonName
+	client, ok := a.clients[cn]
+	if !ok {
+		return "", fmt.Errorf("unknown client certificate %s", cn)
+	}
+	return client, nil
+}
+
+type clientKey struct{}
+
+func withClient(ctx context.Context, client string) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+// clientFromContext returns the authenticated client of a request
+func clientFromContext(ctx context.Context) (string, bool) {
+	client, ok := ctx.Value(clientKey{}).(string)
+	return client, ok
+}
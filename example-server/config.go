@@ -9,18 +9,55 @@ type serverConfig struct {
 	HTTPAddr         string        `goconf:"base:http.addr"`
 	HTTPReadTimeout  time.Duration `goconf:"base:http.read.timeout:time"`
 	HTTPWriteTimeout time.Duration `goconf:"base:http.write.timeout:time"`
+	ShutdownTimeout  time.Duration `goconf:"base:http.shutdown.timeout:time"`
+	ReadyTimeout     time.Duration `goconf:"base:http.ready.timeout:time"`
+	MetricsEnable    bool          `goconf:"base:metrics.enable"`
+	JSONRPC2         bool          `goconf:"base:http.jsonrpc2"`
+	MethodPrefix     string        `goconf:"base:http.method.prefix"`
+	WSEnable         bool          `goconf:"base:ws.enable"`
+	WSOrigins        string        `goconf:"base:ws.origins"`
+	GRPCAddr         string        `goconf:"base:grpc.addr"`
+	AsyncEnable      bool          `goconf:"base:async.enable"`
+	WebhookSecret    string        `goconf:"base:async.webhook.secret"`
+	WebhookAllow     string        `goconf:"base:async.webhook.allow"`
+	WebhookRetries   int           `goconf:"base:async.webhook.retries"`
+	WebhookTimeout   time.Duration `goconf:"base:async.webhook.timeout:time"`
+	ACLFile          string        `goconf:"base:http.acl"`
+	AuthSchemes      string        `goconf:"base:http.auth"`
+	TLSCert          string        `goconf:"base:tls.cert"`
+	TLSKey           string        `goconf:"base:tls.key"`
+	TLSClientCA      string        `goconf:"base:tls.client_ca"`
+	TLSMinVersion    string        `goconf:"base:tls.min_version"`
+	TLSCiphers       string        `goconf:"base:tls.ciphers"`
+	TLSReload        time.Duration `goconf:"base:tls.reload:time"`
 	// for sdk:
-	Keystore    string `goconf:"base:keystore"`
-	RPCProtocal string `goconf:"base:rpc.protocal"`
-	XHost       string `goconf:"base:xhost"`
-	ChainID     int64  `goconf:"base:chain_id"`
-	GetGasPrice bool   `goconf:"base:getgasprice"`
-	Namespace   string `goconf:"base:namespace"`
+	Keystore        string        `goconf:"base:keystore"`
+	RPCProtocal     string        `goconf:"base:rpc.protocal"`
+	XHost           string        `goconf:"base:xhost"`
+	ChainID         int64         `goconf:"base:chain_id"`
+	GetGasPrice     bool          `goconf:"base:getgasprice"`
+	Namespace       string        `goconf:"base:namespace"`
+	CryptoType      string        `goconf:"base:crypto.type"`
+	SignHash        string        `goconf:"base:sign.hash"`
+	HDPath          string        `goconf:"base:hd.path"`
+	UnlockTimeout   time.Duration `goconf:"base:unlock.timeout:time"`
+	PayloadLog      string        `goconf:"base:log.payload"`
+	AuthScheme      string        `goconf:"base:auth.scheme"`
+	AuthReload      time.Duration `goconf:"base:auth.reload:time"`
+	PollInterval    time.Duration `goconf:"base:poll.interval:time"`
+	JournalFile     string        `goconf:"base:journal.file"`
+	IdempotencyTTL  time.Duration `goconf:"base:idempotency.ttl:time"`
+	IdempotencyFile string        `goconf:"base:idempotency.file"`
 }
 
 func newServerConfig() *serverConfig {
 	return &serverConfig{
-		HTTPAddr: "8080",
-		Keystore: "./keystore",
+		HTTPAddr:        "8080",
+		ShutdownTimeout: 30 * time.Second,
+		ReadyTimeout:    3 * time.Second,
+		WebhookRetries:  5,
+		WebhookTimeout:  10 * time.Second,
+		AuthSchemes:     authAPIKey,
+		Keystore:        "./keystore",
 	}
 }
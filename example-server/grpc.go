@@ -0,0 +1,606 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	sdk "github.com/XunleiBlockchain/baas-sdk-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"server/pb"
+)
+
+// grpcErrcodeKey is the trailer carrying the SDK error code of a failed call
+const grpcErrcodeKey = "sdk-errcode"
+
+// grpcServer serves the pb.SDK service, its streams end on shutdown
+type grpcServer struct {
+	*grpc.Server
+	svc *grpcService
+}
+
+// initGRPC starts serving pb.SDK on grpc.addr in the background. Clients authenticate
+// with the same metadata as the HTTP headers, hmac signs an empty body.
+func initGRPC(methods *registry, auth authenticator, certs *certReloader) (*grpcServer, error) {
+	srvLog, err := sdk.NewRedactLogger(logger, conf.PayloadLog)
+	if err != nil {
+		return nil, err
+	}
+	svc := &grpcService{methods: methods, auth: auth, log: srvLog, quit: make(chan struct{})}
+	opts := []grpc.ServerOption{
+		grpc.UnaryInterceptor(svc.unaryInterceptor),
+		grpc.StreamInterceptor(svc.streamInterceptor),
+	}
+	if certs != nil {
+		tlsConf, err := certs.tlsConfig(conf.TLSMinVersion, conf.TLSCiphers)
+		if err != nil {
+			return nil, err
+		}
+		// the per handshake config is cloned from tlsConf, so gRPC's ALPN must be set here
+		tlsConf.NextProtos = []string{"h2"}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConf)))
+	}
+	server := grpc.NewServer(opts...)
+	pb.RegisterSDKServer(server, svc)
+	listener, err := net.Listen("tcp", conf.GRPCAddr)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		if err := server.Serve(listener); err != nil && err != grpc.ErrServerStopped {
+			panic(err)
+		}
+	}()
+	return &grpcServer{Server: server, svc: svc}, nil
+}
+
+// shutdown ends the watch streams and waits for the unary calls until ctx is done
+func (g *grpcServer) shutdown(ctx context.Context) {
+	close(g.svc.quit)
+	done := make(chan struct{})
+	go func() {
+		g.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		g.Stop()
+	}
+}
+
+// grpcService implements pb.SDKServer on the registry, so the ACL and other
+// method middlewares apply as over HTTP
+type grpcService struct {
+	pb.UnimplementedSDKServer
+	methods *registry
+	auth    authenticator // nil不鉴权
+	log     sdk.Logger
+	quit    chan struct{}
+}
+
+// authorize authenticates a call by the metadata and the peer certificate, the
+// idempotency-key metadata is the idempotency key of the call
+func (s *grpcService) authorize(ctx context.Context, method string) (context.Context, error) {
+	r := &http.Request{Method: http.MethodPost, URL: &url.URL{Path: method}, Header: make(http.Header)}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for k, v := range md {
+		r.Header[http.CanonicalHeaderKey(k)] = v
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		r.RemoteAddr = p.Addr.String()
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			r.TLS = &info.State
+		}
+	}
+	ctx = sdk.ExtractTraceContext(ctx, r.Header)
+	var traceparent string
+	if sc, ok := sdk.SpanContextFromContext(ctx); ok {
+		traceparent = sc.TraceParent()
+	}
+	s.log.Info("ServeGRPC", "method", method, "remote", r.RemoteAddr, "traceparent", traceparent)
+	if s.auth != nil {
+		client, err := s.auth.authenticate(r, nil)
+		if err != nil {
+			s.log.Warn("ServeGRPC unauthorized", "method", method, "remote", r.RemoteAddr, "err", err)
+			return nil, status.Error(codes.Unauthenticated, errUnauthorized.Join(err).Msg)
+		}
+		ctx = withClient(ctx, client)
+	}
+	return withIdempotencyKey(ctx, r.Header), nil
+}
+
+func (s *grpcService) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := s.authorize(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// authStream replaces the context of a stream with the authorized one
+type authStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (a *authStream) Context() context.Context {
+	return a.ctx
+}
+
+func (s *grpcService) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authorize(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authStream{ServerStream: ss, ctx: ctx})
+}
+
+// grpcError maps a SDK error into a gRPC status, the SDK code is set in the sdk-errcode trailer
+func grpcError(ctx context.Context, xerr *sdk.Error) error {
+	grpc.SetTrailer(ctx, metadata.Pairs(grpcErrcodeKey, strconv.Itoa(xerr.Code)))
+	code := codes.Unknown
+	switch xerr.Code {
+	case sdk.ErrMethod.Code:
+		code = codes.Unimplemented
+	case sdk.ErrParams.Code:
+		code = codes.InvalidArgument
+	case sdk.ErrInternal.Code:
+		code = codes.Internal
+	case errForbidden.Code:
+		code = codes.PermissionDenied
+	}
+	return status.Error(code, xerr.Msg)
+}
+
+var errNotFound = status.Error(codes.NotFound, "not found")
+
+// call calls the SDK method name through the registry, the result is decoded
+// from its JSON form so numbers are json.Number
+func (s *grpcService) call(ctx context.Context, name string, params ...interface{}) (interface{}, error) {
+	if params == nil {
+		params = []interface{}{}
+	}
+	ret, xerr := s.methods.call(ctx, s.methods.prefix+lowerFirst(name), params)
+	if xerr != nil && xerr.Code != 0 {
+		return nil, grpcError(ctx, xerr)
+	}
+	data, err := json.Marshal(ret)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return v, nil
+}
+
+// object calls name for a block, transaction or receipt, NotFound if there is none
+func (s *grpcService) object(ctx context.Context, name string, params ...interface{}) (map[string]interface{}, error) {
+	v, err := s.call(ctx, name, params...)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, errNotFound
+	}
+	return obj, nil
+}
+
+func (s *grpcService) Accounts(ctx context.Context, req *pb.AccountsRequest) (*pb.AccountsReply, error) {
+	v, err := s.call(ctx, "Accounts")
+	if err != nil {
+		return nil, err
+	}
+	list, _ := v.([]interface{})
+	reply := &pb.AccountsReply{Addresses: make([]string, 0, len(list))}
+	for _, addr := range list {
+		reply.Addresses = append(reply.Addresses, toString(addr))
+	}
+	return reply, nil
+}
+
+func (s *grpcService) NewAccount(ctx context.Context, req *pb.NewAccountRequest) (*pb.NewAccountReply, error) {
+	v, err := s.call(ctx, "NewAccount", req.Passwd)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.NewAccountReply{Address: toString(v)}, nil
+}
+
+func (s *grpcService) GetBalance(ctx context.Context, req *pb.GetBalanceRequest) (*pb.GetBalanceReply, error) {
+	v, err := s.call(ctx, "GetBalance", req.Address)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetBalanceReply{Balance: toDecimal(v)}, nil
+}
+
+func (s *grpcService) GetTransactionCount(ctx context.Context, req *pb.GetTransactionCountRequest) (*pb.GetTransactionCountReply, error) {
+	v, err := s.call(ctx, "GetTransactionCount", req.Address)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetTransactionCountReply{Nonce: toUint64(v)}, nil
+}
+
+func (s *grpcService) BlockNumber(ctx context.Context, req *pb.BlockNumberRequest) (*pb.BlockNumberReply, error) {
+	v, err := s.call(ctx, "BlockNumber")
+	if err != nil {
+		return nil, err
+	}
+	return &pb.BlockNumberReply{Number: toUint64(v)}, nil
+}
+
+func (s *grpcService) GetBlockByNumber(ctx context.Context, req *pb.GetBlockByNumberRequest) (*pb.Block, error) {
+	obj, err := s.object(ctx, "GetBlockByNumber", hexUint(req.Number), req.FullTx)
+	if err != nil {
+		return nil, err
+	}
+	return toBlock(obj), nil
+}
+
+func (s *grpcService) GetBlockByHash(ctx context.Context, req *pb.GetBlockByHashRequest) (*pb.Block, error) {
+	obj, err := s.object(ctx, "GetBlockByHash", req.Hash, req.FullTx)
+	if err != nil {
+		return nil, err
+	}
+	return toBlock(obj), nil
+}
+
+func (s *grpcService) GetTransactionByHash(ctx context.Context, req *pb.GetTransactionByHashRequest) (*pb.Transaction, error) {
+	obj, err := s.object(ctx, "GetTransactionByHash", req.From, req.Hash)
+	if err != nil {
+		return nil, err
+	}
+	return toTransaction(obj), nil
+}
+
+func (s *grpcService) GetTransactionReceipt(ctx context.Context, req *pb.GetTransactionReceiptRequest) (*pb.Receipt, error) {
+	obj, err := s.object(ctx, "GetTransactionReceipt", req.Hash)
+	if err != nil {
+		return nil, err
+	}
+	return toReceipt(obj), nil
+}
+
+func (s *grpcService) SendTransaction(ctx context.Context, req *pb.SendTransactionRequest) (*pb.SendTransactionReply, error) {
+	params := []interface{}{txArgs(req.Tx)}
+	if req.Passwd != "" {
+		params = append(params, req.Passwd)
+	}
+	v, err := s.call(ctx, "SendTransaction", params...)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.SendTransactionReply{Hash: toString(v)}, nil
+}
+
+func (s *grpcService) SendContractTransaction(ctx context.Context, req *pb.SendContractTransactionRequest) (*pb.SendTransactionReply, error) {
+	// [tx], [tx, extension] or [tx, passwd, extension]
+	params := []interface{}{txArgs(req.Tx)}
+	if req.Passwd != "" {
+		params = append(params, req.Passwd, extensionArgs(req.Extension))
+	} else if req.Extension != nil {
+		params = append(params, extensionArgs(req.Extension))
+	}
+	v, err := s.call(ctx, "SendContractTransaction", params...)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.SendTransactionReply{Hash: toString(v)}, nil
+}
+
+func (s *grpcService) Call(ctx context.Context, req *pb.CallRequest) (*pb.CallReply, error) {
+	v, err := s.call(ctx, "Call", map[string]interface{}{
+		"from": req.From,
+		"to":   req.To,
+		"data": "0x" + hex.EncodeToString(req.Data),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.CallReply{Result: toBytes(v)}, nil
+}
+
+func (s *grpcService) SignTx(ctx context.Context, req *pb.SignTxRequest) (*pb.SignTxReply, error) {
+	v, err := s.call(ctx, "SignTx", txArgs(req.Tx))
+	if err != nil {
+		return nil, err
+	}
+	return &pb.SignTxReply{Raw: toBytes(v)}, nil
+}
+
+func (s *grpcService) SendRawTransaction(ctx context.Context, req *pb.SendRawTransactionRequest) (*pb.SendTransactionReply, error) {
+	v, err := s.call(ctx, "SendRawTransaction", "0x"+hex.EncodeToString(req.Raw))
+	if err != nil {
+		return nil, err
+	}
+	return &pb.SendTransactionReply{Hash: toString(v)}, nil
+}
+
+// streamSubscriber takes the subscription of a watch stream
+type streamSubscriber struct {
+	sub *sdk.Subscription
+}
+
+func (s *streamSubscriber) add(sub *sdk.Subscription) error {
+	s.sub = sub
+	return nil
+}
+
+// subscribe subscribes through the registry's subscribe method
+func (s *grpcService) subscribe(ctx context.Context, params ...interface{}) (*sdk.Subscription, error) {
+	sub := &streamSubscriber{}
+	if _, err := s.call(context.WithValue(ctx, subscriberKey{}, subscriber(sub)), "subscribe", params...); err != nil {
+		return nil, err
+	}
+	return sub.sub, nil
+}
+
+// next returns the next notification of sub, an error when the stream is to end
+func (s *grpcService) next(ctx context.Context, sub *sdk.Subscription) (interface{}, error) {
+	select {
+	case v, ok := <-sub.C():
+		if !ok {
+			msg := "subscription ended"
+			if err := sub.Err(); err != nil {
+				msg += ": " + err.Error()
+			}
+			return nil, status.Error(codes.Aborted, msg)
+		}
+		return v, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-s.quit:
+		return nil, status.Error(codes.Unavailable, "server shutting down")
+	}
+}
+
+func (s *grpcService) WatchBlocks(req *pb.WatchBlocksRequest, stream pb.SDK_WatchBlocksServer) error {
+	ctx := stream.Context()
+	sub, err := s.subscribe(ctx, sdk.SubNewHeads)
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+	for {
+		v, err := s.next(ctx, sub)
+		if err != nil {
+			return err
+		}
+		block, _ := jsonObject(v)
+		if err := stream.Send(toBlock(block)); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *grpcService) WatchReceipts(req *pb.WatchReceiptsRequest, stream pb.SDK_WatchReceiptsServer) error {
+	ctx := stream.Context()
+	params := []interface{}{sdk.SubPendingTxStatus}
+	remaining := make(map[string]bool)
+	if len(req.Hashes) != 0 {
+		hashes := make([]interface{}, len(req.Hashes))
+		for i, h := range req.Hashes {
+			hashes[i] = h
+			remaining[strings.ToLower(h)] = true
+		}
+		params = append(params, map[string]interface{}{"hashes": hashes})
+	}
+	sub, err := s.subscribe(ctx, params...)
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+	for {
+		v, err := s.next(ctx, sub)
+		if err != nil {
+			return err
+		}
+		st, ok := v.(*sdk.TxStatus)
+		if !ok {
+			continue
+		}
+		msg := &pb.TxStatus{Hash: st.Hash.Hex(), Status: st.Status}
+		if receipt, ok := jsonObject(st.Receipt); ok {
+			msg.Receipt = toReceipt(receipt)
+		}
+		if err := stream.Send(msg); err != nil {
+			return err
+		}
+		if len(req.Hashes) != 0 && st.Status != sdk.TxPending {
+			delete(remaining, strings.ToLower(msg.Hash))
+			if len(remaining) == 0 {
+				return nil
+			}
+		}
+	}
+}
+
+// ------------------------------- conversion -------------------------------
+func txArgs(tx *pb.TxArgs) map[string]interface{} {
+	if tx == nil {
+		return map[string]interface{}{}
+	}
+	args := map[string]interface{}{"from": tx.From, "to": tx.To}
+	if tx.Value != "" {
+		args["value"] = tx.Value
+	}
+	if tx.Gas != nil {
+		args["gas"] = hexUint(*tx.Gas)
+	}
+	if tx.GasPrice != "" {
+		args["gasPrice"] = tx.GasPrice
+	}
+	if tx.Nonce != nil {
+		args["nonce"] = hexUint(*tx.Nonce)
+	}
+	if len(tx.Data) != 0 {
+		args["data"] = "0x" + hex.EncodeToString(tx.Data)
+	}
+	return args
+}
+
+func extensionArgs(ext *pb.ContractExtension) map[string]interface{} {
+	args := map[string]interface{}{}
+	if ext == nil {
+		return args
+	}
+	for k, v := range map[string]string{
+		"callback":   ext.Callback,
+		"prepay_id":  ext.PrepayId,
+		"service_id": ext.ServiceId,
+		"tx_type":    ext.TxType,
+		"sign":       ext.Sign,
+		"title":      ext.Title,
+		"desc":       ext.Desc,
+	} {
+		if v != "" {
+			args[k] = v
+		}
+	}
+	return args
+}
+
+// jsonObject converts a BaaS object into its JSON form with json.Number numbers
+func jsonObject(v interface{}) (map[string]interface{}, bool) {
+	if v == nil {
+		return nil, false
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, false
+	}
+	var obj map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if dec.Decode(&obj) != nil || obj == nil {
+		return nil, false
+	}
+	return obj, true
+}
+
+func hexUint(n uint64) string {
+	return "0x" + strconv.FormatUint(n, 16)
+}
+
+func toString(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+// toBig parses a json.Number, 0x hex or decimal string
+func toBig(v interface{}) *big.Int {
+	var s string
+	switch x := v.(type) {
+	case json.Number:
+		s = x.String()
+	case string:
+		s = x
+	}
+	n, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return new(big.Int)
+	}
+	return n
+}
+
+func toUint64(v interface{}) uint64 {
+	return toBig(v).Uint64()
+}
+
+func toDecimal(v interface{}) string {
+	return toBig(v).String()
+}
+
+func toBytes(v interface{}) []byte {
+	b, _ := hex.DecodeString(strings.TrimPrefix(toString(v), "0x"))
+	return b
+}
+
+func toBlock(obj map[string]interface{}) *pb.Block {
+	block := &pb.Block{
+		Number:     toUint64(obj["number"]),
+		Hash:       toString(obj["hash"]),
+		ParentHash: toString(obj["parentHash"]),
+		Timestamp:  toUint64(obj["timestamp"]),
+		GasUsed:    toUint64(obj["gasUsed"]),
+	}
+	txs, _ := obj["transactions"].([]interface{})
+	for _, tx := range txs {
+		if t, ok := tx.(map[string]interface{}); ok {
+			block.Transactions = append(block.Transactions, toTransaction(t))
+			block.TransactionHashes = append(block.TransactionHashes, toString(t["hash"]))
+		} else {
+			block.TransactionHashes = append(block.TransactionHashes, toString(tx))
+		}
+	}
+	return block
+}
+
+func toTransaction(obj map[string]interface{}) *pb.Transaction {
+	return &pb.Transaction{
+		Hash:             toString(obj["hash"]),
+		From:             toString(obj["from"]),
+		To:               toString(obj["to"]),
+		Value:            toDecimal(obj["value"]),
+		Gas:              toUint64(obj["gas"]),
+		GasPrice:         toDecimal(obj["gasPrice"]),
+		Nonce:            toUint64(obj["nonce"]),
+		Input:            toBytes(obj["input"]),
+		BlockHash:        toString(obj["blockHash"]),
+		BlockNumber:      toUint64(obj["blockNumber"]),
+		TransactionIndex: toUint64(obj["transactionIndex"]),
+	}
+}
+
+func toReceipt(obj map[string]interface{}) *pb.Receipt {
+	receipt := &pb.Receipt{
+		TransactionHash:   toString(obj["transactionHash"]),
+		TransactionIndex:  toUint64(obj["transactionIndex"]),
+		BlockHash:         toString(obj["blockHash"]),
+		BlockNumber:       toUint64(obj["blockNumber"]),
+		From:              toString(obj["from"]),
+		To:                toString(obj["to"]),
+		GasUsed:           toUint64(obj["gasUsed"]),
+		CumulativeGasUsed: toUint64(obj["cumulativeGasUsed"]),
+		ContractAddress:   toString(obj["contractAddress"]),
+		Success:           toUint64(obj["status"]) == 1,
+	}
+	logs, _ := obj["logs"].([]interface{})
+	for _, l := range logs {
+		log, ok := l.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		entry := &pb.Log{
+			Address:  toString(log["address"]),
+			Data:     toBytes(log["data"]),
+			LogIndex: toUint64(log["logIndex"]),
+		}
+		topics, _ := log["topics"].([]interface{})
+		for _, t := range topics {
+			entry.Topics = append(entry.Topics, toString(t))
+		}
+		receipt.Logs = append(receipt.Logs, entry)
+	}
+	return receipt
+}
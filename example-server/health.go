@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	sdk "github.com/XunleiBlockchain/baas-sdk-go"
+)
+
+// health serves the probes, they bypass client authentication
+type health struct {
+	sdk     *sdk.SDKImpl
+	timeout time.Duration // /readyz 访问BaaS的超时时间
+}
+
+func newHealth(mySDK *sdk.SDKImpl, timeout time.Duration) *health {
+	return &health{sdk: mySDK, timeout: timeout}
+}
+
+// healthz answers 200 as long as the process serves http
+func (h *health) healthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("content-type", "application/json")
+	writeJSON(w, map[string]string{"status": "ok"})
+}
+
+// readyz answers 200 when BaaS is reachable, the configured accounts are unlocked
+// and the gas price is known, 503 with the failed checks otherwise
+func (h *health) readyz(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+	res := h.sdk.Ready(ctx)
+	w.Header().Set("content-type", "application/json")
+	if !res.Ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	writeJSON(w, res)
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	sdk "github.com/XunleiBlockchain/baas-sdk-go"
+)
+
+// JSON-RPC 2.0 error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+)
+
+// rpcError is the error object of a JSON-RPC 2.0 response
+type rpcError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// rpcErrorOf maps a SDK error into a JSON-RPC 2.0 error object, the SDK code is kept in data
+func rpcErrorOf(xerr *sdk.Error) *rpcError {
+	code := xerr.Code
+	switch xerr.Code {
+	case sdk.ErrMethod.Code:
+		code = codeMethodNotFound
+	case sdk.ErrParams.Code:
+		code = codeInvalidParams
+	case sdk.ErrInternal.Code:
+		code = codeInternalError
+	}
+	return &rpcError{
+		Code:    code,
+		Message: xerr.Msg,
+		Data:    map[string]interface{}{"errcode": xerr.Code},
+	}
+}
+
+func invalidRequest(reason string) *rpcError {
+	return &rpcError{Code: codeInvalidRequest, Message: "Invalid Request", Data: reason}
+}
+
+func newRPCResult(id json.RawMessage, result interface{}) map[string]interface{} {
+	return map[string]interface{}{"jsonrpc": "2.0", "id": id, "result": result}
+}
+
+func newRPCError(id json.RawMessage, err *rpcError) map[string]interface{} {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return map[string]interface{}{"jsonrpc": "2.0", "id": id, "error": err}
+}
+
+// respondJSONRPC2 serves a JSON-RPC 2.0 request or batch. Notifications get no
+// response, nil for a request of notifications only.
+func (srv *Server) respondJSONRPC2(ctx context.Context, r *http.Request, traceparent string, body []byte) interface{} {
+	var msg json.RawMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		srv.logRequest(r, traceparent, "", nil, body)
+		return newRPCError(nil, &rpcError{Code: codeParseError, Message: "Parse error", Data: err.Error()})
+	}
+	if msg = bytes.TrimSpace(msg); msg[0] != '[' {
+		if resp := srv.handleJSONRPC2(ctx, r, traceparent, msg); resp != nil {
+			return resp
+		}
+		return nil
+	}
+	var batch []json.RawMessage
+	json.Unmarshal(msg, &batch)
+	if len(batch) == 0 {
+		return newRPCError(nil, invalidRequest("empty batch"))
+	}
+	// one key cannot cover several sends, it is ignored in a batch
+	ctx = sdk.ContextWithIdempotencyKey(ctx, "")
+	resps := make([]interface{}, 0, len(batch))
+	for _, m := range batch {
+		if resp := srv.handleJSONRPC2(ctx, r, traceparent, m); resp != nil {
+			resps = append(resps, resp)
+		}
+	}
+	if len(resps) == 0 {
+		return nil
+	}
+	return resps
+}
+
+// handleJSONRPC2 serves one request object, nil for a notification
+func (srv *Server) handleJSONRPC2(ctx context.Context, r *http.Request, traceparent string, msg json.RawMessage) map[string]interface{} {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(msg, &fields); err != nil {
+		srv.logRequest(r, traceparent, "", nil, msg)
+		return newRPCError(nil, invalidRequest("request must be an object"))
+	}
+	id, ok := fields["id"]
+	notification := !ok
+	if ok && !validID(id) {
+		srv.logRequest(r, traceparent, "", nil, msg)
+		return newRPCError(nil, invalidRequest("id must be a string, number or null"))
+	}
+	var (
+		version string
+		method  string
+		params  interface{}
+	)
+	if json.Unmarshal(fields["jsonrpc"], &version) != nil || version != "2.0" {
+		srv.logRequest(r, traceparent, "", nil, msg)
+		return newRPCError(id, invalidRequest(`jsonrpc must be "2.0"`))
+	}
+	if json.Unmarshal(fields["method"], &method) != nil || method == "" {
+		srv.logRequest(r, traceparent, "", nil, msg)
+		return newRPCError(id, invalidRequest("method must be a string"))
+	}
+	if raw, ok := fields["params"]; ok {
+		json.Unmarshal(raw, &params)
+		switch params.(type) {
+		case []interface{}, map[string]interface{}:
+		default:
+			srv.logRequest(r, traceparent, method, nil, msg)
+			return newRPCError(id, invalidRequest("params must be an array or object"))
+		}
+	}
+	srv.logRequest(r, traceparent, method, params, msg)
+	ret, xerr := srv.methods.call(ctx, method, params)
+	if notification {
+		return nil
+	}
+	if xerr != nil && xerr.Code != 0 {
+		return newRPCError(id, rpcErrorOf(xerr))
+	}
+	return newRPCResult(id, ret)
+}
+
+// validID reports whether id is a string, number or null
+func validID(id json.RawMessage) bool {
+	var v interface{}
+	if err := json.Unmarshal(id, &v); err != nil {
+		return false
+	}
+	switch v.(type) {
+	case nil, string, float64:
+		return true
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	data, _ := json.Marshal(v)
+	w.Write(data)
+}
@@ -1,14 +1,20 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"io/ioutil"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/Terry-Mao/goconf"
 	"github.com/binacsgo/log"
 
 	sdk "github.com/XunleiBlockchain/baas-sdk-go"
+	"github.com/XunleiBlockchain/baas-sdk-go/metrics"
 )
 
 var (
@@ -17,38 +23,84 @@ var (
 	confFile     string
 	passwdFile   string
 	authInfoFile string
+	mnemonicFile string
 )
 
 func init() {
 	flag.StringVar(&confFile, "c", "./sdk-server.conf", " set sdk-server config file path")
 	flag.StringVar(&passwdFile, "p", "", " set password json file path")
 	flag.StringVar(&authInfoFile, "a", "", " set auth info json file path")
+	flag.StringVar(&mnemonicFile, "m", "", " set hd wallet mnemonic file path")
 }
 
 func main() {
 	flag.Parse()
-	// 1. init serverConfig and sdk.Config
+	// 1. init logger
+	logger = log.Sugar()
+
+	// 2. init serverConfig and sdk.Config
 	sdkConf, err := initServerConfig()
 	if err != nil {
 		panic(err)
 	}
 
-	// 2. init logger
-	logger = log.Sugar()
-
 	// 3. new sdk
 	mySDK, err := sdk.NewSDK(sdkConf, logger)
 	if err != nil {
 		panic(err)
 	}
 
-	// 4. start HTTPServer
+	for addr, reason := range mySDK.UnlockErrors() {
+		logger.Warn("unlock account fail", "account", addr, "err", reason)
+	}
+
+	// 4. start HTTPServer and gRPC server
 	logger.Info("sdk-server start.")
-	if err := initHTTP(mySDK); err != nil {
+	jobs, err := initAsync(mySDK)
+	if err != nil {
 		panic(err)
 	}
+	methods, auth, err := initMethods(mySDK, jobs)
+	if err != nil {
+		panic(err)
+	}
+	certs, err := initTLS()
+	if err != nil {
+		panic(err)
+	}
+	httpServer, err := initHTTP(mySDK, methods, auth, certs)
+	if err != nil {
+		panic(err)
+	}
+	var grpcServer *grpcServer
+	if conf.GRPCAddr != "" {
+		if grpcServer, err = initGRPC(methods, auth, certs); err != nil {
+			panic(err)
+		}
+	}
 
-	select {}
+	// 5. on SIGTERM/SIGINT stop accepting requests, drain the in-flight ones and close sdk
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGTERM, syscall.SIGINT)
+	logger.Info("sdk-server stopping.", "signal", (<-sig).String())
+	ctx := context.Background()
+	if conf.ShutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, conf.ShutdownTimeout)
+		defer cancel()
+	}
+	if err := httpServer.Shutdown(ctx); err != nil {
+		logger.Warn("sdk-server drain timeout, closing connections", "err", err)
+		httpServer.Close()
+	}
+	if grpcServer != nil {
+		grpcServer.shutdown(ctx)
+	}
+	if jobs != nil {
+		jobs.close(ctx)
+	}
+	mySDK.Close()
+	logger.Info("sdk-server stopped.")
 }
 
 func initServerConfig() (*sdk.Config, error) {
@@ -69,8 +121,45 @@ func initServerConfig() (*sdk.Config, error) {
 		Namespace:      conf.Namespace,
 		ChainID:        conf.ChainID,
 		GetGasPrice:    conf.GetGasPrice,
+		CryptoType:     conf.CryptoType,
+		SignHash:       conf.SignHash,
+		HDPath:         conf.HDPath,
+		UnlockTimeout:  conf.UnlockTimeout,
+		PayloadLog:     conf.PayloadLog,
+		AuthScheme:     conf.AuthScheme,
+		PollInterval:   conf.PollInterval,
+		IdempotencyTTL: conf.IdempotencyTTL,
+	}
+	if conf.JournalFile != "" {
+		j, err := sdk.NewFileJournal(conf.JournalFile, logger)
+		if err != nil {
+			return nil, err
+		}
+		sdkConf.Journal = j
+	}
+	if conf.IdempotencyFile != "" {
+		s, err := sdk.NewFileIdempotencyStore(conf.IdempotencyFile, logger)
+		if err != nil {
+			return nil, err
+		}
+		sdkConf.Idempotency = s
+	}
+	if conf.MetricsEnable {
+		m, err := metrics.NewPrometheus(nil, "baas_sdk")
+		if err != nil {
+			return nil, err
+		}
+		sdkConf.Metrics = m
 	}
-	if authInfoFile != "" {
+	switch {
+	case authInfoFile != "" && conf.AuthReload > 0:
+		creds, err := sdk.NewFileCredentials(authInfoFile, conf.AuthReload, logger)
+		if err != nil {
+			return nil, err
+		}
+		sdkConf.Credentials = creds
+		logger.Info("credentials from file, reloaded", "file", authInfoFile, "interval", conf.AuthReload)
+	case authInfoFile != "":
 		authInfoJSON, err := ioutil.ReadFile(authInfoFile)
 		if err != nil {
 			return nil, err
@@ -79,6 +168,11 @@ func initServerConfig() (*sdk.Config, error) {
 		if err != nil {
 			return nil, err
 		}
+		logger.Info("credentials from file", "file", authInfoFile)
+	default:
+		// without -a the credentials come from BAAS_CHAINID, BAAS_ID and BAAS_KEY
+		sdkConf.Credentials = sdk.EnvCredentials{Prefix: "BAAS_"}
+		logger.Warn("no -a auth.json given, credentials from the environment", "vars", "BAAS_CHAINID BAAS_ID BAAS_KEY")
 	}
 	if passwdFile != "" {
 		passwdsJSON, err := ioutil.ReadFile(passwdFile)
@@ -90,5 +184,12 @@ func initServerConfig() (*sdk.Config, error) {
 			return nil, err
 		}
 	}
+	if mnemonicFile != "" {
+		mnemonic, err := ioutil.ReadFile(mnemonicFile)
+		if err != nil {
+			return nil, err
+		}
+		sdkConf.Mnemonic = strings.TrimSpace(string(mnemonic))
+	}
 	return sdkConf, nil
 }
@@ -0,0 +1,698 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion7
+
+// SDKClient is the client API for SDK service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SDKClient interface {
+	Accounts(ctx context.Context, in *AccountsRequest, opts ...grpc.CallOption) (*AccountsReply, error)
+	NewAccount(ctx context.Context, in *NewAccountRequest, opts ...grpc.CallOption) (*NewAccountReply, error)
+	GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*GetBalanceReply, error)
+	GetTransactionCount(ctx context.Context, in *GetTransactionCountRequest, opts ...grpc.CallOption) (*GetTransactionCountReply, error)
+	BlockNumber(ctx context.Context, in *BlockNumberRequest, opts ...grpc.CallOption) (*BlockNumberReply, error)
+	GetBlockByNumber(ctx context.Context, in *GetBlockByNumberRequest, opts ...grpc.CallOption) (*Block, error)
+	GetBlockByHash(ctx context.Context, in *GetBlockByHashRequest, opts ...grpc.CallOption) (*Block, error)
+	GetTransactionByHash(ctx context.Context, in *GetTransactionByHashRequest, opts ...grpc.CallOption) (*Transaction, error)
+	GetTransactionReceipt(ctx context.Context, in *GetTransactionReceiptRequest, opts ...grpc.CallOption) (*Receipt, error)
+	SendTransaction(ctx context.Context, in *SendTransactionRequest, opts ...grpc.CallOption) (*SendTransactionReply, error)
+	SendContractTransaction(ctx context.Context, in *SendContractTransactionRequest, opts ...grpc.CallOption) (*SendTransactionReply, error)
+	Call(ctx context.Context, in *CallRequest, opts ...grpc.CallOption) (*CallReply, error)
+	SignTx(ctx context.Context, in *SignTxRequest, opts ...grpc.CallOption) (*SignTxReply, error)
+	SendRawTransaction(ctx context.Context, in *SendRawTransactionRequest, opts ...grpc.CallOption) (*SendTransactionReply, error)
+	// WatchBlocks streams the blocks after the current one
+	WatchBlocks(ctx context.Context, in *WatchBlocksRequest, opts ...grpc.CallOption) (SDK_WatchBlocksClient, error)
+	// WatchReceipts streams the status of transactions sent through this server, or
+	// of the given hashes until all of them are mined or dropped
+	WatchReceipts(ctx context.Context, in *WatchReceiptsRequest, opts ...grpc.CallOption) (SDK_WatchReceiptsClient, error)
+}
+
+type sDKClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSDKClient(cc grpc.ClientConnInterface) SDKClient {
+	return &sDKClient{cc}
+}
+
+func (c *sDKClient) Accounts(ctx context.Context, in *AccountsRequest, opts ...grpc.CallOption) (*AccountsReply, error) {
+	out := new(AccountsReply)
+	err := c.cc.Invoke(ctx, "/sdkserver.SDK/Accounts", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sDKClient) NewAccount(ctx context.Context, in *NewAccountRequest, opts ...grpc.CallOption) (*NewAccountReply, error) {
+	out := new(NewAccountReply)
+	err := c.cc.Invoke(ctx, "/sdkserver.SDK/NewAccount", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sDKClient) GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*GetBalanceReply, error) {
+	out := new(GetBalanceReply)
+	err := c.cc.Invoke(ctx, "/sdkserver.SDK/GetBalance", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sDKClient) GetTransactionCount(ctx context.Context, in *GetTransactionCountRequest, opts ...grpc.CallOption) (*GetTransactionCountReply, error) {
+	out := new(GetTransactionCountReply)
+	err := c.cc.Invoke(ctx, "/sdkserver.SDK/GetTransactionCount", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sDKClient) BlockNumber(ctx context.Context, in *BlockNumberRequest, opts ...grpc.CallOption) (*BlockNumberReply, error) {
+	out := new(BlockNumberReply)
+	err := c.cc.Invoke(ctx, "/sdkserver.SDK/BlockNumber", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sDKClient) GetBlockByNumber(ctx context.Context, in *GetBlockByNumberRequest, opts ...grpc.CallOption) (*Block, error) {
+	out := new(Block)
+	err := c.cc.Invoke(ctx, "/sdkserver.SDK/GetBlockByNumber", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sDKClient) GetBlockByHash(ctx context.Context, in *GetBlockByHashRequest, opts ...grpc.CallOption) (*Block, error) {
+	out := new(Block)
+	err := c.cc.Invoke(ctx, "/sdkserver.SDK/GetBlockByHash", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sDKClient) GetTransactionByHash(ctx context.Context, in *GetTransactionByHashRequest, opts ...grpc.CallOption) (*Transaction, error) {
+	out := new(Transaction)
+	err := c.cc.Invoke(ctx, "/sdkserver.SDK/GetTransactionByHash", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sDKClient) GetTransactionReceipt(ctx context.Context, in *GetTransactionReceiptRequest, opts ...grpc.CallOption) (*Receipt, error) {
+	out := new(Receipt)
+	err := c.cc.Invoke(ctx, "/sdkserver.SDK/GetTransactionReceipt", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sDKClient) SendTransaction(ctx context.Context, in *SendTransactionRequest, opts ...grpc.CallOption) (*SendTransactionReply, error) {
+	out := new(SendTransactionReply)
+	err := c.cc.Invoke(ctx, "/sdkserver.SDK/SendTransaction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sDKClient) SendContractTransaction(ctx context.Context, in *SendContractTransactionRequest, opts ...grpc.CallOption) (*SendTransactionReply, error) {
+	out := new(SendTransactionReply)
+	err := c.cc.Invoke(ctx, "/sdkserver.SDK/SendContractTransaction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sDKClient) Call(ctx context.Context, in *CallRequest, opts ...grpc.CallOption) (*CallReply, error) {
+	out := new(CallReply)
+	err := c.cc.Invoke(ctx, "/sdkserver.SDK/Call", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sDKClient) SignTx(ctx context.Context, in *SignTxRequest, opts ...grpc.CallOption) (*SignTxReply, error) {
+	out := new(SignTxReply)
+	err := c.cc.Invoke(ctx, "/sdkserver.SDK/SignTx", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sDKClient) SendRawTransaction(ctx context.Context, in *SendRawTransactionRequest, opts ...grpc.CallOption) (*SendTransactionReply, error) {
+	out := new(SendTransactionReply)
+	err := c.cc.Invoke(ctx, "/sdkserver.SDK/SendRawTransaction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sDKClient) WatchBlocks(ctx context.Context, in *WatchBlocksRequest, opts ...grpc.CallOption) (SDK_WatchBlocksClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_SDK_serviceDesc.Streams[0], "/sdkserver.SDK/WatchBlocks", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &sDKWatchBlocksClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type SDK_WatchBlocksClient interface {
+	Recv() (*Block, error)
+	grpc.ClientStream
+}
+
+type sDKWatchBlocksClient struct {
+	grpc.ClientStream
+}
+
+func (x *sDKWatchBlocksClient) Recv() (*Block, error) {
+	m := new(Block)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *sDKClient) WatchReceipts(ctx context.Context, in *WatchReceiptsRequest, opts ...grpc.CallOption) (SDK_WatchReceiptsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_SDK_serviceDesc.Streams[1], "/sdkserver.SDK/WatchReceipts", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &sDKWatchReceiptsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type SDK_WatchReceiptsClient interface {
+	Recv() (*TxStatus, error)
+	grpc.ClientStream
+}
+
+type sDKWatchReceiptsClient struct {
+	grpc.ClientStream
+}
+
+func (x *sDKWatchReceiptsClient) Recv() (*TxStatus, error) {
+	m := new(TxStatus)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// SDKServer is the server API for SDK service.
+// All implementations must embed UnimplementedSDKServer
+// for forward compatibility
+type SDKServer interface {
+	Accounts(context.Context, *AccountsRequest) (*AccountsReply, error)
+	NewAccount(context.Context, *NewAccountRequest) (*NewAccountReply, error)
+	GetBalance(context.Context, *GetBalanceRequest) (*GetBalanceReply, error)
+	GetTransactionCount(context.Context, *GetTransactionCountRequest) (*GetTransactionCountReply, error)
+	BlockNumber(context.Context, *BlockNumberRequest) (*BlockNumberReply, error)
+	GetBlockByNumber(context.Context, *GetBlockByNumberRequest) (*Block, error)
+	GetBlockByHash(context.Context, *GetBlockByHashRequest) (*Block, error)
+	GetTransactionByHash(context.Context, *GetTransactionByHashRequest) (*Transaction, error)
+	GetTransactionReceipt(context.Context, *GetTransactionReceiptRequest) (*Receipt, error)
+	SendTransaction(context.Context, *SendTransactionRequest) (*SendTransactionReply, error)
+	SendContractTransaction(context.Context, *SendContractTransactionRequest) (*SendTransactionReply, error)
+	Call(context.Context, *CallRequest) (*CallReply, error)
+	SignTx(context.Context, *SignTxRequest) (*SignTxReply, error)
+	SendRawTransaction(context.Context, *SendRawTransactionRequest) (*SendTransactionReply, error)
+	// WatchBlocks streams the blocks after the current one
+	WatchBlocks(*WatchBlocksRequest, SDK_WatchBlocksServer) error
+	// WatchReceipts streams the status of transactions sent through this server, or
+	// of the given hashes until all of them are mined or dropped
+	WatchReceipts(*WatchReceiptsRequest, SDK_WatchReceiptsServer) error
+	mustEmbedUnimplementedSDKServer()
+}
+
+// UnimplementedSDKServer must be embedded to have forward compatible implementations.
+type UnimplementedSDKServer struct {
+}
+
+func (UnimplementedSDKServer) Accounts(context.Context, *AccountsRequest) (*AccountsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Accounts not implemented")
+}
+func (UnimplementedSDKServer) NewAccount(context.Context, *NewAccountRequest) (*NewAccountReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NewAccount not implemented")
+}
+func (UnimplementedSDKServer) GetBalance(context.Context, *GetBalanceRequest) (*GetBalanceReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBalance not implemented")
+}
+func (UnimplementedSDKServer) GetTransactionCount(context.Context, *GetTransactionCountRequest) (*GetTransactionCountReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransactionCount not implemented")
+}
+func (UnimplementedSDKServer) BlockNumber(context.Context, *BlockNumberRequest) (*BlockNumberReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BlockNumber not implemented")
+}
+func (UnimplementedSDKServer) GetBlockByNumber(context.Context, *GetBlockByNumberRequest) (*Block, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlockByNumber not implemented")
+}
+func (UnimplementedSDKServer) GetBlockByHash(context.Context, *GetBlockByHashRequest) (*Block, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlockByHash not implemented")
+}
+func (UnimplementedSDKServer) GetTransactionByHash(context.Context, *GetTransactionByHashRequest) (*Transaction, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransactionByHash not implemented")
+}
+func (UnimplementedSDKServer) GetTransactionReceipt(context.Context, *GetTransactionReceiptRequest) (*Receipt, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransactionReceipt not implemented")
+}
+func (UnimplementedSDKServer) SendTransaction(context.Context, *SendTransactionRequest) (*SendTransactionReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendTransaction not implemented")
+}
+func (UnimplementedSDKServer) SendContractTransaction(context.Context, *SendContractTransactionRequest) (*SendTransactionReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendContractTransaction not implemented")
+}
+func (UnimplementedSDKServer) Call(context.Context, *CallRequest) (*CallReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Call not implemented")
+}
+func (UnimplementedSDKServer) SignTx(context.Context, *SignTxRequest) (*SignTxReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SignTx not implemented")
+}
+func (UnimplementedSDKServer) SendRawTransaction(context.Context, *SendRawTransactionRequest) (*SendTransactionReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendRawTransaction not implemented")
+}
+func (UnimplementedSDKServer) WatchBlocks(*WatchBlocksRequest, SDK_WatchBlocksServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchBlocks not implemented")
+}
+func (UnimplementedSDKServer) WatchReceipts(*WatchReceiptsRequest, SDK_WatchReceiptsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchReceipts not implemented")
+}
+func (UnimplementedSDKServer) mustEmbedUnimplementedSDKServer() {}
+
+// UnsafeSDKServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SDKServer will
+// result in compilation errors.
+type UnsafeSDKServer interface {
+	mustEmbedUnimplementedSDKServer()
+}
+
+func RegisterSDKServer(s grpc.ServiceRegistrar, srv SDKServer) {
+	s.RegisterService(&_SDK_serviceDesc, srv)
+}
+
+func _SDK_Accounts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AccountsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SDKServer).Accounts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sdkserver.SDK/Accounts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SDKServer).Accounts(ctx, req.(*AccountsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SDK_NewAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NewAccountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SDKServer).NewAccount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sdkserver.SDK/NewAccount",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SDKServer).NewAccount(ctx, req.(*NewAccountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SDK_GetBalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBalanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SDKServer).GetBalance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sdkserver.SDK/GetBalance",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SDKServer).GetBalance(ctx, req.(*GetBalanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SDK_GetTransactionCount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTransactionCountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SDKServer).GetTransactionCount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sdkserver.SDK/GetTransactionCount",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SDKServer).GetTransactionCount(ctx, req.(*GetTransactionCountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SDK_BlockNumber_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockNumberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SDKServer).BlockNumber(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sdkserver.SDK/BlockNumber",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SDKServer).BlockNumber(ctx, req.(*BlockNumberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SDK_GetBlockByNumber_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlockByNumberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SDKServer).GetBlockByNumber(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sdkserver.SDK/GetBlockByNumber",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SDKServer).GetBlockByNumber(ctx, req.(*GetBlockByNumberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SDK_GetBlockByHash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlockByHashRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SDKServer).GetBlockByHash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sdkserver.SDK/GetBlockByHash",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SDKServer).GetBlockByHash(ctx, req.(*GetBlockByHashRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SDK_GetTransactionByHash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTransactionByHashRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SDKServer).GetTransactionByHash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sdkserver.SDK/GetTransactionByHash",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SDKServer).GetTransactionByHash(ctx, req.(*GetTransactionByHashRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SDK_GetTransactionReceipt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTransactionReceiptRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SDKServer).GetTransactionReceipt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sdkserver.SDK/GetTransactionReceipt",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SDKServer).GetTransactionReceipt(ctx, req.(*GetTransactionReceiptRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SDK_SendTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SDKServer).SendTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sdkserver.SDK/SendTransaction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SDKServer).SendTransaction(ctx, req.(*SendTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SDK_SendContractTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendContractTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SDKServer).SendContractTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sdkserver.SDK/SendContractTransaction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SDKServer).SendContractTransaction(ctx, req.(*SendContractTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SDK_Call_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CallRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SDKServer).Call(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sdkserver.SDK/Call",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SDKServer).Call(ctx, req.(*CallRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SDK_SignTx_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignTxRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SDKServer).SignTx(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sdkserver.SDK/SignTx",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SDKServer).SignTx(ctx, req.(*SignTxRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SDK_SendRawTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendRawTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SDKServer).SendRawTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sdkserver.SDK/SendRawTransaction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SDKServer).SendRawTransaction(ctx, req.(*SendRawTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SDK_WatchBlocks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchBlocksRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SDKServer).WatchBlocks(m, &sDKWatchBlocksServer{stream})
+}
+
+type SDK_WatchBlocksServer interface {
+	Send(*Block) error
+	grpc.ServerStream
+}
+
+type sDKWatchBlocksServer struct {
+	grpc.ServerStream
+}
+
+func (x *sDKWatchBlocksServer) Send(m *Block) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _SDK_WatchReceipts_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchReceiptsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SDKServer).WatchReceipts(m, &sDKWatchReceiptsServer{stream})
+}
+
+type SDK_WatchReceiptsServer interface {
+	Send(*TxStatus) error
+	grpc.ServerStream
+}
+
+type sDKWatchReceiptsServer struct {
+	grpc.ServerStream
+}
+
+func (x *sDKWatchReceiptsServer) Send(m *TxStatus) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _SDK_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "sdkserver.SDK",
+	HandlerType: (*SDKServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Accounts",
+			Handler:    _SDK_Accounts_Handler,
+		},
+		{
+			MethodName: "NewAccount",
+			Handler:    _SDK_NewAccount_Handler,
+		},
+		{
+			MethodName: "GetBalance",
+			Handler:    _SDK_GetBalance_Handler,
+		},
+		{
+			MethodName: "GetTransactionCount",
+			Handler:    _SDK_GetTransactionCount_Handler,
+		},
+		{
+			MethodName: "BlockNumber",
+			Handler:    _SDK_BlockNumber_Handler,
+		},
+		{
+			MethodName: "GetBlockByNumber",
+			Handler:    _SDK_GetBlockByNumber_Handler,
+		},
+		{
+			MethodName: "GetBlockByHash",
+			Handler:    _SDK_GetBlockByHash_Handler,
+		},
+		{
+			MethodName: "GetTransactionByHash",
+			Handler:    _SDK_GetTransactionByHash_Handler,
+		},
+		{
+			MethodName: "GetTransactionReceipt",
+			Handler:    _SDK_GetTransactionReceipt_Handler,
+		},
+		{
+			MethodName: "SendTransaction",
+			Handler:    _SDK_SendTransaction_Handler,
+		},
+		{
+			MethodName: "SendContractTransaction",
+			Handler:    _SDK_SendContractTransaction_Handler,
+		},
+		{
+			MethodName: "Call",
+			Handler:    _SDK_Call_Handler,
+		},
+		{
+			MethodName: "SignTx",
+			Handler:    _SDK_SignTx_Handler,
+		},
+		{
+			MethodName: "SendRawTransaction",
+			Handler:    _SDK_SendRawTransaction_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchBlocks",
+			Handler:       _SDK_WatchBlocks_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchReceipts",
+			Handler:       _SDK_WatchReceipts_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "pb/sdk.proto",
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	sdk "github.com/XunleiBlockchain/baas-sdk-go"
+)
+
+// handler serves one RPC method
+type handler func(ctx context.Context, params interface{}) (interface{}, *sdk.Error)
+
+// methodMiddleware wraps the handler of the named RPC method, e.g. for auth or rate limiting
+type methodMiddleware func(method string, next handler) handler
+
+type method struct {
+	name   string
+	desc   string
+	params []paramSchema
+	secret bool // 参数含密码等 日志中字符串参数打码
+	h      handler
+	mws    []methodMiddleware
+
+	secretResult bool // 返回结果含助记词等 不得记录或缓存
+}
+
+// registry maps RPC method names to handlers. Methods and middlewares are
+// registered before serving, it is not safe to change while serving.
+type registry struct {
+	prefix  string
+	methods map[string]*method
+}
+
+var (
+	sdkType   = reflect.TypeOf((*sdk.SDK)(nil)).Elem()
+	errorType = reflect.TypeOf((*sdk.Error)(nil))
+)
+
+// newRegistry registers all methods of the sdk.SDK interface as prefix + lower camel
+// case name, e.g. sdk_getBalance, and the rpc_methods introspection method.
+func newRegistry(mySDK *sdk.SDKImpl, prefix string) (*registry, error) {
+	reg := &registry{prefix: prefix, methods: make(map[string]*method)}
+	for i := 0; i < sdkType.NumMethod(); i++ {
+		m := sdkType.Method(i)
+		if m.Type.NumIn() > 1 || m.Type.NumOut() != 2 || m.Type.Out(1) != errorType {
+			return nil, fmt.Errorf("registry: unsupported signature of SDK.%s", m.Name)
+		}
+		schema, ok := sdkSchemas[m.Name]
+		if !ok {
+			// unknown params may carry passwords
+			schema.secret = true
+		}
+		reg.register(prefix+lowerFirst(m.Name), schema, sdkHandler(mySDK, m.Name, m.Type.NumIn() == 1))
+	}
+	reg.register("rpc_methods", methodSchema{desc: "lists the available methods and their params"}, reg.listMethods)
+	return reg, nil
+}
+
+// sdkHandler calls the SDK method name on mySDK bound to the request context
+func sdkHandler(mySDK *sdk.SDKImpl, name string, withParams bool) handler {
+	return func(ctx context.Context, params interface{}) (interface{}, *sdk.Error) {
+		// the idempotency and prepay_id keys of clients never collide
+		if client, ok := clientFromContext(ctx); ok {
+			ctx = sdk.ContextWithIdempotencyScope(ctx, client)
+		}
+		fn := reflect.ValueOf(mySDK.WithContext(ctx)).MethodByName(name)
+		var in []reflect.Value
+		if withParams {
+			in = []reflect.Value{reflect.ValueOf(&params).Elem()}
+		}
+		out := fn.Call(in)
+		xerr, _ := out[1].Interface().(*sdk.Error)
+		return out[0].Interface(), xerr
+	}
+}
+
+func (reg *registry) register(name string, schema methodSchema, h handler) {
+	secret := schema.secret
+	for _, p := range schema.params {
+		secret = secret || p.Secret
+	}
+	reg.methods[name] = &method{
+		name:   name,
+		desc:   schema.desc,
+		params: schema.params,
+		secret: secret,
+		h:      h,
+
+		secretResult: schema.secretResult,
+	}
+}
+
+// use adds mw to the named methods, to all methods if none is given. The
+// middleware added first runs outermost.
+func (reg *registry) use(mw methodMiddleware, names ...string) error {
+	if len(names) == 0 {
+		for _, m := range reg.methods {
+			m.mws = append(m.mws, mw)
+		}
+		return nil
+	}
+	for _, name := range names {
+		m, ok := reg.methods[name]
+		if !ok {
+			return fmt.Errorf("registry: unknown method %s", name)
+		}
+		m.mws = append(m.mws, mw)
+	}
+	return nil
+}
+
+// call serves the named method, ErrMethod if it is not registered
+func (reg *registry) call(ctx context.Context, name string, params interface{}) (interface{}, *sdk.Error) {
+	m, ok := reg.methods[name]
+	if !ok {
+		return nil, sdk.ErrMethod
+	}
+	h := m.h
+	for i := len(m.mws) - 1; i >= 0; i-- {
+		h = m.mws[i](name, h)
+	}
+	return h(ctx, params)
+}
+
+// secret reports whether the params of the named method must be masked in logs
+func (reg *registry) secret(name string) bool {
+	m, ok := reg.methods[name]
+	return ok && m.secret
+}
+
+// secretResult reports whether the result of the named method must not be logged or cached
+func (reg *registry) secretResult(name string) bool {
+	m, ok := reg.methods[name]
+	return ok && m.secretResult
+}
+
+type methodInfo struct {
+	Name         string        `json:"name"`
+	Description  string        `json:"description,omitempty"`
+	Params       []paramSchema `json:"params"`
+	SecretResult bool          `json:"secretResult,omitempty"` // 返回结果不得记录或缓存
+}
+
+func (reg *registry) listMethods(ctx context.Context, params interface{}) (interface{}, *sdk.Error) {
+	names := make([]string, 0, len(reg.methods))
+	for name := range reg.methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	res := make([]methodInfo, 0, len(names))
+	for _, name := range names {
+		m := reg.methods[name]
+		info := methodInfo{Name: m.name, Description: m.desc, Params: m.params, SecretResult: m.secretResult}
+		if info.Params == nil {
+			info.Params = []paramSchema{}
+		}
+		res = append(res, info)
+	}
+	return res, nil
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
@@ -0,0 +1,166 @@
+package main
+
+// Param types of paramSchema
+const (
+	typeString   = "string"
+	typeBool     = "bool"
+	typeAddress  = "address"  // 20字节十六进制地址
+	typeHash     = "hash"     // 32字节十六进制哈希
+	typeQuantity = "quantity" // JSON数字 0x十六进制或十进制字符串
+	typeHex      = "hex"      // 0x十六进制字节串
+	typeObject   = "object"
+)
+
+// paramSchema describes one positional param, listed by rpc_methods
+type paramSchema struct {
+	Name     string        `json:"name"`
+	Type     string        `json:"type"`
+	Required bool          `json:"required"`
+	Secret   bool          `json:"secret,omitempty"` // 密码等 日志中打码
+	Fields   []paramSchema `json:"fields,omitempty"` // object的字段
+}
+
+type methodSchema struct {
+	desc         string
+	params       []paramSchema
+	secret       bool
+	secretResult bool // 返回结果含助记词等 不得记录或缓存
+}
+
+func required(name, typ string) paramSchema {
+	return paramSchema{Name: name, Type: typ, Required: true}
+}
+
+func optional(name, typ string) paramSchema {
+	return paramSchema{Name: name, Type: typ}
+}
+
+func password(name string, req bool) paramSchema {
+	return paramSchema{Name: name, Type: typeString, Required: req, Secret: true}
+}
+
+func object(name string, req bool, fields ...paramSchema) paramSchema {
+	return paramSchema{Name: name, Type: typeObject, Required: req, Fields: fields}
+}
+
+var txFields = []paramSchema{
+	required("from", typeAddress),
+	required("to", typeAddress),
+	optional("value", typeQuantity),
+	optional("gas", typeQuantity),
+	optional("gasPrice", typeQuantity),
+	optional("nonce", typeQuantity),
+	optional("data", typeHex),
+}
+
+var extensionFields = []paramSchema{
+	optional("callback", typeString),
+	optional("prepay_id", typeString),
+	optional("service_id", typeString),
+	optional("tx_type", typeString),
+	optional("sign", typeString),
+	optional("title", typeString),
+	optional("desc", typeString),
+}
+
+// sdkSchemas are the params of the sdk.SDK methods by Go method name
+var sdkSchemas = map[string]methodSchema{
+	"NewAccount": {desc: "creates an account", params: []paramSchema{
+		password("passwd", true),
+	}},
+	"Accounts": {desc: "lists the account addresses"},
+	"GetBalance": {desc: "returns the balance of an account", params: []paramSchema{
+		required("address", typeAddress),
+	}},
+	"GetTransactionCount": {desc: "returns the nonce of an account", params: []paramSchema{
+		required("address", typeAddress),
+	}},
+	"BlockNumber": {desc: "returns the latest block number"},
+	"GetTransactionByHash": {desc: "returns a transaction", params: []paramSchema{
+		required("from", typeAddress),
+		required("hash", typeHash),
+	}},
+	"GetTransactionReceipt": {desc: "returns a transaction receipt", params: []paramSchema{
+		required("hash", typeHash),
+	}},
+	"GetBlockByNumber": {desc: "returns a block by number", params: []paramSchema{
+		required("number", typeQuantity),
+		optional("fullTx", typeBool),
+	}},
+	"GetBlockByHash": {desc: "returns a block by hash", params: []paramSchema{
+		required("hash", typeHash),
+		optional("fullTx", typeBool),
+	}},
+	"SendTransaction": {desc: "signs and sends a transfer", params: []paramSchema{
+		object("tx", true, txFields...),
+		password("passwd", false),
+	}},
+	"SendContractTransaction": {desc: "signs and sends a contract transaction, with 2 params the second is the extension", params: []paramSchema{
+		object("tx", true, txFields...),
+		password("passwd", false),
+		object("extension", false, extensionFields...),
+	}},
+	"Call": {desc: "executes a contract call without a transaction", params: []paramSchema{
+		object("call", true,
+			required("from", typeAddress),
+			required("to", typeAddress),
+			optional("data", typeHex),
+		),
+	}},
+	"SignTx": {desc: "signs a transaction with an unlocked account and returns the raw transaction", params: []paramSchema{
+		object("tx", true, txFields...),
+	}},
+	"SendRawTransaction": {desc: "sends a signed raw transaction", params: []paramSchema{
+		required("raw", typeHex),
+	}},
+	"NewMnemonic": {desc: "generates and imports a HD wallet mnemonic and returns it, the caller must persist it", secretResult: true, params: []paramSchema{
+		optional("bits", typeQuantity),
+		optional("overwrite", typeBool),
+	}},
+	"ImportMnemonic": {desc: "imports a HD wallet mnemonic", params: []paramSchema{
+		password("mnemonic", true),
+		password("passphrase", false),
+		optional("overwrite", typeBool),
+	}},
+	"DeriveAddress": {desc: "derives the HD wallet address at index", params: []paramSchema{
+		required("index", typeQuantity),
+	}},
+	"DeriveAccount": {desc: "derives the HD wallet account at index into keystore", params: []paramSchema{
+		required("index", typeQuantity),
+		password("passwd", true),
+	}},
+	"ImportRawKey": {desc: "imports a hex private key", params: []paramSchema{
+		{Name: "key", Type: typeHex, Required: true, Secret: true},
+		password("passwd", true),
+	}},
+	"ImportKeystore": {desc: "imports a keystore json given as object or string", params: []paramSchema{
+		required("keystore", typeObject),
+		password("passwd", true),
+		password("newPasswd", false),
+	}},
+	"ExportKeystore": {desc: "exports the keystore json of an account", params: []paramSchema{
+		required("address", typeAddress),
+		password("passwd", true),
+		password("newPasswd", false),
+	}},
+	"UpdatePassword": {desc: "changes the password of an account", params: []paramSchema{
+		required("address", typeAddress),
+		password("passwd", true),
+		password("newPasswd", true),
+	}},
+	"DeleteAccount": {desc: "deletes an account", params: []paramSchema{
+		required("address", typeAddress),
+		password("passwd", true),
+	}},
+	"UnlockAccount": {desc: "unlocks an account for duration seconds, 0 means indefinitely", params: []paramSchema{
+		required("address", typeAddress),
+		password("passwd", true),
+		optional("duration", typeQuantity),
+	}},
+	"LockAccount": {desc: "locks an account", params: []paramSchema{
+		required("address", typeAddress),
+	}},
+	"AccountStatus": {desc: "returns whether an account is unlocked and until when", params: []paramSchema{
+		required("address", typeAddress),
+	}},
+}
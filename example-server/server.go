@@ -1,12 +1,18 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"io/ioutil"
 	"net"
 	"net/http"
+	"sync"
 
 	sdk "github.com/XunleiBlockchain/baas-sdk-go"
+	"github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 type request struct {
@@ -16,24 +22,82 @@ type request struct {
 	Params  interface{} `json:"params"`
 }
 
+// maskStringParams masks the top level string params, tx args objects are kept
+func maskStringParams(params interface{}) interface{} {
+	args, ok := params.([]interface{})
+	if !ok {
+		return params
+	}
+	res := make([]interface{}, len(args))
+	for i, arg := range args {
+		if _, ok := arg.(string); ok {
+			arg = "******"
+		}
+		res[i] = arg
+	}
+	return res
+}
+
 // Server serve http
 type Server struct {
-	mySDK *sdk.SDKImpl
+	methods  *registry
+	log      sdk.Logger
+	jsonrpc2 bool          // 使用JSON-RPC 2.0规范的请求/响应格式
+	auth     authenticator // 客户端鉴权 nil不鉴权
+
+	upgrader *websocket.Upgrader // nil不提供 /ws
+	wsMu     sync.Mutex
+	wsConns  map[*wsConn]bool // 退出时关闭 nil表示正在退出
 }
 
-func newServer(mySDK *sdk.SDKImpl) *Server {
+func newServer(methods *registry, log sdk.Logger, jsonrpc2 bool, auth authenticator) *Server {
 	return &Server{
-		mySDK: mySDK,
+		methods:  methods,
+		log:      log,
+		jsonrpc2: jsonrpc2,
+		auth:     auth,
 	}
 }
 
 func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	body, _ := ioutil.ReadAll(r.Body)
-	logger.Info("ServeHTTP", "url", r.URL, "params", string(body))
 	w.Header().Set("content-type", "application/json")
+	// continue the caller's trace, if any, down to BaaS
+	ctx := sdk.ExtractTraceContext(r.Context(), r.Header)
+	var traceparent string
+	if sc, ok := sdk.SpanContextFromContext(ctx); ok {
+		traceparent = sc.TraceParent()
+	}
+	if srv.auth != nil {
+		client, err := srv.auth.authenticate(r, body)
+		if err != nil {
+			srv.log.Warn("ServeHTTP unauthorized", "url", r.URL, "remote", r.RemoteAddr, "err", err)
+			srv.reject(w, http.StatusUnauthorized, errUnauthorized.Join(err))
+			return
+		}
+		ctx = withClient(ctx, client)
+	}
+	ctx = withIdempotencyKey(ctx, r.Header)
+	if srv.secretResult(body) {
+		w.Header().Set("Cache-Control", "no-store")
+	}
+	resp := srv.respond(ctx, r, traceparent, body)
+	if resp == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, resp)
+}
+
+// respond serves a request body in the configured format, nil if nothing is to be answered
+func (srv *Server) respond(ctx context.Context, r *http.Request, traceparent string, body []byte) interface{} {
+	if srv.jsonrpc2 {
+		return srv.respondJSONRPC2(ctx, r, traceparent, body)
+	}
 	var req request
 	resp := make(map[string]interface{})
 	err := json.Unmarshal(body, &req)
+	srv.logRequest(r, traceparent, req.Method, req.Params, body)
 	if err != nil {
 		resp["id"] = req.ID
 		resp["jsonrpc"] = req.Jsonrpc
@@ -41,55 +105,9 @@ func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		resp["errcode"] = xerr.Code
 		resp["errmsg"] = xerr.Msg
 		//resp["result"] = fmt.Sprintf("err: %v", err)
-		respByte, _ := json.Marshal(resp)
-		w.Write(respByte)
-		return
-	}
-	var ret interface{}
-	var xerr *sdk.Error
-	switch req.Method {
-	case "accounts":
-		ret, xerr = srv.mySDK.Accounts(req.Params)
-		break
-	case "newAccount":
-		ret, xerr = srv.mySDK.NewAccount(req.Params)
-		break
-	case "getBalance":
-		ret, xerr = srv.mySDK.GetBalance(req.Params)
-		break
-	case "getTransactionCount":
-		ret, xerr = srv.mySDK.GetTransactionCount(req.Params)
-		break
-	case "blockNumber":
-		ret, xerr = srv.mySDK.BlockNumber()
-		break
-	case "getTransactionByHash":
-		ret, xerr = srv.mySDK.GetTransactionByHash(req.Params)
-		break
-	case "getTransactionReceipt":
-		ret, xerr = srv.mySDK.GetTransactionReceipt(req.Params)
-		break
-	case "getBlockByNumber":
-		ret, xerr = srv.mySDK.GetBlockByNumber(req.Params)
-		break
-	case "getBlockByHash":
-		ret, xerr = srv.mySDK.GetBlockByHash(req.Params)
-		break
-	case "sendTransaction":
-		ret, xerr = srv.mySDK.SendTransaction(req.Params)
-		break
-	case "sendContractTransaction":
-		ret, xerr = srv.mySDK.SendContractTransaction(req.Params)
-		break
-	case "call":
-		ret, xerr = srv.mySDK.Call(req.Params)
-		break
-	case "signTx":
-		ret, xerr = srv.mySDK.SignTx(req.Params)
-		break
-	default:
-		xerr = sdk.ErrMethod
+		return resp
 	}
+	ret, xerr := srv.methods.call(ctx, req.Method, req.Params)
 	resp["id"] = req.ID
 	resp["jsonrpc"] = req.Jsonrpc
 	resp["result"] = ret
@@ -99,19 +117,153 @@ func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	resp["errcode"] = xerr.Code
 	resp["errmsg"] = xerr.Msg
 	//resp["result"] = fmt.Sprintf("err: %v", err)
-	respByte, err := json.Marshal(resp)
-	w.Write(respByte)
-	return
+	return resp
 }
 
-func initHTTP(mySDK *sdk.SDKImpl) (err error) {
-	server := newServer(mySDK)
-	httpServer := &http.Server{Handler: server, ReadTimeout: conf.HTTPReadTimeout, WriteTimeout: conf.HTTPWriteTimeout}
+// withIdempotencyKey makes the Idempotency-Key header the SDK idempotency key of
+// ctx, sdkHandler scopes it to the client
+func withIdempotencyKey(ctx context.Context, header http.Header) context.Context {
+	key := header.Get(headerIdempotencyKey)
+	if key == "" {
+		return ctx
+	}
+	return sdk.ContextWithIdempotencyKey(ctx, key)
+}
+
+// secretResult reports whether the request or batch body calls a method whose result
+// must not be logged or cached by proxies
+func (srv *Server) secretResult(body []byte) bool {
+	type call struct {
+		Method string `json:"method"`
+	}
+	var calls []call
+	if json.Unmarshal(body, &calls) != nil {
+		var c call
+		json.Unmarshal(body, &c)
+		calls = []call{c}
+	}
+	for _, c := range calls {
+		if srv.methods.secretResult(c.Method) {
+			return true
+		}
+	}
+	return false
+}
+
+// reject answers a request that is not served with status and xerr
+func (srv *Server) reject(w http.ResponseWriter, status int, xerr *sdk.Error) {
+	w.WriteHeader(status)
+	if srv.jsonrpc2 {
+		writeJSON(w, newRPCError(nil, rpcErrorOf(xerr)))
+		return
+	}
+	writeJSON(w, map[string]interface{}{"errcode": xerr.Code, "errmsg": xerr.Msg})
+}
+
+// logRequest logs a request, the string params of methods carrying passwords are masked
+func (srv *Server) logRequest(r *http.Request, traceparent, method string, params interface{}, body []byte) {
+	if srv.methods.secret(method) {
+		params, _ := json.Marshal(maskStringParams(params))
+		srv.log.Info("ServeHTTP", "url", r.URL, "traceparent", traceparent, "method", method, "params", sdk.Payload(params))
+	} else {
+		srv.log.Info("ServeHTTP", "url", r.URL, "traceparent", traceparent, "params", sdk.Payload(body))
+	}
+}
+
+// initMethods returns the RPC methods served over HTTP, WebSocket and gRPC, and
+// the client authenticator, nil if no ACL is configured. The async methods are
+// registered if jobs is not nil.
+func initMethods(mySDK *sdk.SDKImpl, jobs *asyncJobs) (*registry, authenticator, error) {
+	methods, err := newRegistry(mySDK, conf.MethodPrefix)
+	if err != nil {
+		return nil, nil, err
+	}
+	if conf.WSEnable || conf.GRPCAddr != "" {
+		registerSubscriptions(methods, mySDK)
+	}
+	if jobs != nil {
+		registerAsync(methods, jobs)
+	}
+	var auth authenticator
+	if conf.ACLFile != "" {
+		rules, err := loadACL(conf.ACLFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		if auth, err = rules.authenticator(conf.AuthSchemes); err != nil {
+			return nil, nil, err
+		}
+		if err = methods.use(rules.middleware(methods)); err != nil {
+			return nil, nil, err
+		}
+		if jobs != nil {
+			jobs.secrets = rules.webhookSecrets
+		}
+	}
+	return methods, auth, nil
+}
+
+// initAsync returns the async jobs runner, nil unless async.enable
+func initAsync(mySDK *sdk.SDKImpl) (*asyncJobs, error) {
+	if !conf.AsyncEnable {
+		return nil, nil
+	}
+	// with an ACL the clients may have their own webhook secrets
+	if conf.WebhookSecret == "" && conf.ACLFile == "" {
+		return nil, errors.New("async.webhook.secret is required without http.acl")
+	}
+	jobLog, err := sdk.NewRedactLogger(logger, conf.PayloadLog)
+	if err != nil {
+		return nil, err
+	}
+	return newAsyncJobs(mySDK, conf.WebhookSecret, conf.WebhookAllow, conf.WebhookRetries, conf.WebhookTimeout, jobLog)
+}
+
+// initTLS loads the server certificate shared by the HTTP and gRPC listeners, nil without tls.cert
+func initTLS() (*certReloader, error) {
+	if conf.TLSCert == "" {
+		return nil, nil
+	}
+	return newCertReloader(conf.TLSCert, conf.TLSKey, conf.TLSClientCA, conf.TLSReload, logger)
+}
+
+// initHTTP starts serving in the background, the returned server is shut down on exit
+func initHTTP(mySDK *sdk.SDKImpl, methods *registry, auth authenticator, certs *certReloader) (*http.Server, error) {
+	srvLog, err := sdk.NewRedactLogger(logger, conf.PayloadLog)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	srv := newServer(methods, srvLog, conf.JSONRPC2, auth)
+	mux.Handle("/", srv)
+	if conf.WSEnable {
+		srv.enableWebSocket(conf.WSOrigins)
+		mux.HandleFunc("/ws", srv.serveWebSocket)
+	}
+	if conf.MetricsEnable {
+		mux.Handle("/metrics", promhttp.Handler())
+	}
+	probes := newHealth(mySDK, conf.ReadyTimeout)
+	mux.HandleFunc("/healthz", probes.healthz)
+	mux.HandleFunc("/readyz", probes.readyz)
+	httpServer := &http.Server{Handler: mux, ReadTimeout: conf.HTTPReadTimeout, WriteTimeout: conf.HTTPWriteTimeout}
 	httpServer.SetKeepAlivesEnabled(true)
+	httpServer.RegisterOnShutdown(srv.closeWebSockets)
 	listener, err := net.Listen("tcp", conf.HTTPAddr)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if certs != nil {
+		tlsConf, err := certs.tlsConfig(conf.TLSMinVersion, conf.TLSCiphers)
+		if err != nil {
+			return nil, err
+		}
+		listener = tls.NewListener(listener, tlsConf)
 	}
-	err = httpServer.Serve(listener)
-	return
+	go func() {
+		if err := httpServer.Serve(listener); err != http.ErrServerClosed {
+			panic(err)
+		}
+	}()
+	return httpServer, nil
 }
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/XunleiBlockchain/baas-sdk-go"
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// certReloader holds the server certificate and client CA pool, reloading them
+// when any of the files changes. Files failing to load keep the previous ones.
+type certReloader struct {
+	certFile string
+	keyFile  string
+	caFile   string // 为空不校验客户端证书
+	log      sdk.Logger
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	pool     *x509.CertPool
+	modTimes []time.Time
+}
+
+func newCertReloader(certFile, keyFile, caFile string, interval time.Duration, log sdk.Logger) (*certReloader, error) {
+	c := &certReloader{certFile: certFile, keyFile: keyFile, caFile: caFile, log: log}
+	if err := c.reload(); err != nil {
+		return nil, err
+	}
+	if interval > 0 {
+		go c.loop(interval)
+	}
+	return c, nil
+}
+
+func (c *certReloader) files() []string {
+	files := []string{c.certFile, c.keyFile}
+	if c.caFile != "" {
+		files = append(files, c.caFile)
+	}
+	return files
+}
+
+func (c *certReloader) reload() error {
+	files := c.files()
+	modTimes := make([]time.Time, len(files))
+	for i, f := range files {
+		fi, err := os.Stat(f)
+		if err != nil {
+			return err
+		}
+		modTimes[i] = fi.ModTime()
+	}
+	c.mu.RLock()
+	unchanged := c.modTimes != nil
+	for i := range c.modTimes {
+		unchanged = unchanged && c.modTimes[i].Equal(modTimes[i])
+	}
+	c.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return err
+	}
+	var pool *x509.CertPool
+	if c.caFile != "" {
+		pem, err := ioutil.ReadFile(c.caFile)
+		if err != nil {
+			return err
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificate in %s", c.caFile)
+		}
+	}
+	c.mu.Lock()
+	c.cert, c.pool, c.modTimes = &cert, pool, modTimes
+	c.mu.Unlock()
+	c.log.Info("tls certificate loaded", "cert", c.certFile, "clientCA", c.caFile)
+	return nil
+}
+
+func (c *certReloader) loop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := c.reload(); err != nil {
+			c.log.Error("tls certificate reload fail", "cert", c.certFile, "err", err)
+		}
+	}
+}
+
+// tlsConfig returns the listener config, every handshake uses the current
+// certificate and client CA pool
+func (c *certReloader) tlsConfig(minVersion string, ciphers string) (*tls.Config, error) {
+	base := &tls.Config{MinVersion: tls.VersionTLS12}
+	if minVersion != "" {
+		v, ok := tlsVersions[minVersion]
+		if !ok {
+			return nil, fmt.Errorf("unknown tls.min_version %s", minVersion)
+		}
+		base.MinVersion = v
+	}
+	if ciphers != "" {
+		suites, err := parseCipherSuites(ciphers)
+		if err != nil {
+			return nil, err
+		}
+		base.CipherSuites = suites
+	}
+	if c.caFile != "" {
+		base.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		c.mu.RLock()
+		defer c.mu.RUnlock()
+		cfg := base.Clone()
+		cfg.GetConfigForClient = nil
+		cfg.Certificates = []tls.Certificate{*c.cert}
+		cfg.ClientCAs = c.pool
+		return cfg, nil
+	}
+	return base, nil
+}
+
+// parseCipherSuites parses comma separated cipher suite names such as
+// TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, only secure suites are accepted.
+// They apply up to TLS 1.2, TLS 1.3 suites are not configurable.
+func parseCipherSuites(names string) ([]uint16, error) {
+	known := make(map[string]uint16)
+	for _, s := range tls.CipherSuites() {
+		known[s.Name] = s.ID
+	}
+	var res []uint16
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		id, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown or insecure tls cipher suite %s", name)
+		}
+		res = append(res, id)
+	}
+	if len(res) == 0 {
+		return nil, errors.New("empty tls.ciphers")
+	}
+	return res, nil
+}
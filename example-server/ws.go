@@ -0,0 +1,291 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/XunleiBlockchain/baas-sdk-go"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsReadLimit        = 1 << 20          // 单条消息上限
+	wsWriteWait        = 10 * time.Second // 写超时
+	wsPongWait         = 60 * time.Second // 未收到pong视为断开
+	wsPingPeriod       = wsPongWait / 2
+	wsMaxInflight      = 16 // 每个连接并发处理的请求数
+	wsMaxSubscriptions = 64 // 每个连接的订阅数
+)
+
+// subscriber takes the subscriptions made by subscribe, the WebSocket connection
+// or gRPC stream of the request
+type subscriber interface {
+	add(sub *sdk.Subscription) error
+}
+
+type subscriberKey struct{}
+
+// wsConn is a WebSocket connection with its subscriptions
+type wsConn struct {
+	conn *websocket.Conn
+	log  sdk.Logger
+
+	wmu sync.Mutex // 同一时间只允许一个写
+
+	mu     sync.Mutex
+	subs   map[string]*sdk.Subscription
+	closed bool
+}
+
+// enableWebSocket serves /ws, origins are the comma separated allowed browser
+// origins, empty allows same origin only
+func (srv *Server) enableWebSocket(origins string) {
+	srv.upgrader = &websocket.Upgrader{}
+	if origins != "" {
+		allowed := make(map[string]bool)
+		for _, o := range strings.Split(origins, ",") {
+			allowed[strings.TrimSpace(o)] = true
+		}
+		srv.upgrader.CheckOrigin = func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				return true
+			}
+			u, err := url.Parse(origin)
+			return err == nil && (allowed["*"] || allowed[origin] || strings.EqualFold(u.Host, r.Host))
+		}
+	}
+	srv.wsConns = make(map[*wsConn]bool)
+}
+
+// serveWebSocket serves the RPC methods, subscribe and unsubscribe over a WebSocket.
+// Clients authenticate on the upgrade request, hmac signs an empty body.
+func (srv *Server) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	ctx := sdk.ExtractTraceContext(context.Background(), r.Header)
+	var traceparent string
+	if sc, ok := sdk.SpanContextFromContext(ctx); ok {
+		traceparent = sc.TraceParent()
+	}
+	if srv.auth != nil {
+		client, err := srv.auth.authenticate(r, nil)
+		if err != nil {
+			srv.log.Warn("serveWebSocket unauthorized", "url", r.URL, "remote", r.RemoteAddr, "err", err)
+			w.Header().Set("content-type", "application/json")
+			srv.reject(w, http.StatusUnauthorized, errUnauthorized.Join(err))
+			return
+		}
+		ctx = withClient(ctx, client)
+	}
+	conn, err := srv.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		srv.log.Warn("serveWebSocket upgrade fail", "remote", r.RemoteAddr, "err", err)
+		return
+	}
+	c := &wsConn{conn: conn, log: srv.log, subs: make(map[string]*sdk.Subscription)}
+	if !srv.trackConn(c, true) {
+		c.close(websocket.CloseGoingAway)
+		return
+	}
+	defer srv.trackConn(c, false)
+	defer c.close(websocket.CloseNormalClosure)
+	ctx, cancel := context.WithCancel(context.WithValue(ctx, subscriberKey{}, subscriber(c)))
+	defer cancel()
+	go c.ping(ctx)
+
+	conn.SetReadLimit(wsReadLimit)
+	conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+	inflight := make(chan struct{}, wsMaxInflight)
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				srv.log.Warn("serveWebSocket read fail", "remote", r.RemoteAddr, "err", err)
+			}
+			return
+		}
+		inflight <- struct{}{}
+		go func() {
+			defer func() { <-inflight }()
+			if resp := srv.respond(ctx, r, traceparent, msg); resp != nil {
+				c.write(resp)
+			}
+		}()
+	}
+}
+
+// trackConn adds or removes c from the connections closed on shutdown, false
+// if the server is shutting down
+func (srv *Server) trackConn(c *wsConn, add bool) bool {
+	srv.wsMu.Lock()
+	defer srv.wsMu.Unlock()
+	if !add {
+		delete(srv.wsConns, c)
+		return true
+	}
+	if srv.wsConns == nil {
+		return false
+	}
+	srv.wsConns[c] = true
+	return true
+}
+
+// closeWebSockets closes the WebSocket connections on shutdown, http.Server.Shutdown
+// does not track them
+func (srv *Server) closeWebSockets() {
+	srv.wsMu.Lock()
+	conns := srv.wsConns
+	srv.wsConns = nil
+	srv.wsMu.Unlock()
+	for c := range conns {
+		c.close(websocket.CloseGoingAway)
+	}
+}
+
+func (c *wsConn) write(v interface{}) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	return c.conn.WriteJSON(v)
+}
+
+func (c *wsConn) ping(ctx context.Context) {
+	ticker := time.NewTicker(wsPingPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.wmu.Lock()
+			err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait))
+			c.wmu.Unlock()
+			if err != nil {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// close ends the subscriptions and closes the connection with code
+func (c *wsConn) close(code int) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return
+	}
+	c.closed = true
+	subs := c.subs
+	c.subs = nil
+	c.mu.Unlock()
+	for _, sub := range subs {
+		sub.Unsubscribe()
+	}
+	c.wmu.Lock()
+	c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, ""), time.Now().Add(wsWriteWait))
+	c.wmu.Unlock()
+	c.conn.Close()
+}
+
+// add forwards the notifications of sub until it ends
+func (c *wsConn) add(sub *sdk.Subscription) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return errors.New("connection closed")
+	}
+	if len(c.subs) >= wsMaxSubscriptions {
+		return fmt.Errorf("at most %d subscriptions per connection", wsMaxSubscriptions)
+	}
+	c.subs[sub.ID] = sub
+	go c.forward(sub)
+	return nil
+}
+
+func (c *wsConn) forward(sub *sdk.Subscription) {
+	for v := range sub.C() {
+		c.write(newNotification(sub.ID, "result", v))
+	}
+	c.mu.Lock()
+	_, active := c.subs[sub.ID]
+	delete(c.subs, sub.ID)
+	c.mu.Unlock()
+	if err := sub.Err(); err != nil && active {
+		c.log.Warn("subscription ended", "id", sub.ID, "kind", sub.Kind, "err", err)
+		c.write(newNotification(sub.ID, "error", err.Error()))
+	}
+}
+
+func (c *wsConn) remove(id string) bool {
+	c.mu.Lock()
+	sub, ok := c.subs[id]
+	delete(c.subs, id)
+	c.mu.Unlock()
+	if ok {
+		sub.Unsubscribe()
+	}
+	return ok
+}
+
+// newNotification returns a subscription message, key is result or error
+func newNotification(id, key string, v interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "subscription",
+		"params":  map[string]interface{}{"subscription": id, key: v},
+	}
+}
+
+// registerSubscriptions registers prefix + subscribe and unsubscribe, served over /ws.
+// gRPC watch streams subscribe through the former.
+func registerSubscriptions(reg *registry, mySDK *sdk.SDKImpl) {
+	reg.register(reg.prefix+"subscribe", methodSchema{
+		desc: "subscribes to newHeads, pendingTxStatus (filter {hashes}) or logs (filter {address, topics}) over /ws, returns the subscription id",
+		params: []paramSchema{
+			required("kind", typeString),
+			optional("filter", typeObject),
+		},
+	}, func(ctx context.Context, params interface{}) (interface{}, *sdk.Error) {
+		c, ok := ctx.Value(subscriberKey{}).(subscriber)
+		if !ok {
+			return nil, sdk.ErrMethod.Join(errors.New("subscribe is served over /ws and gRPC streams only"))
+		}
+		sub, xerr := mySDK.WithContext(ctx).Subscribe(params)
+		if xerr != nil {
+			return nil, xerr
+		}
+		if err := c.add(sub); err != nil {
+			sub.Unsubscribe()
+			return nil, sdk.ErrSubscribe.Join(err)
+		}
+		return sub.ID, nil
+	})
+	reg.register(reg.prefix+"unsubscribe", methodSchema{
+		desc: "ends a subscription of the connection, returns whether it existed",
+		params: []paramSchema{
+			required("id", typeString),
+		},
+	}, func(ctx context.Context, params interface{}) (interface{}, *sdk.Error) {
+		c, ok := ctx.Value(subscriberKey{}).(*wsConn)
+		if !ok {
+			return nil, sdk.ErrMethod.Join(errors.New("unsubscribe is served over /ws only"))
+		}
+		args, _ := params.([]interface{})
+		if len(args) != 1 {
+			return nil, sdk.ErrParams.Join(errors.New("params: expected 1 element"))
+		}
+		id, ok := args[0].(string)
+		if !ok {
+			return nil, sdk.ErrParams.Join(errors.New("params[0]: expected subscription id string"))
+		}
+		return c.remove(id), nil
+	})
+}
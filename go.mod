@@ -4,4 +4,13 @@ go 1.13
 
 replace github.com/tjfoc/gmsm => github.com/bcscb8/gmsm v0.0.0-20191220070229-b97b35b41ab6
 
-require github.com/XunleiBlockchain/tc-libs v0.0.0-20200921075319-ce7d804eeb2f
+require (
+	github.com/XunleiBlockchain/tc-libs v0.0.0-20200921075319-ce7d804eeb2f
+	github.com/golang/protobuf v1.4.3 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/tjfoc/gmsm v0.0.0-00010101000000-000000000000
+	github.com/tyler-smith/go-bip39 v1.1.0
+	golang.org/x/sys v0.0.0-20210603081109-ebe580a85c40 // indirect
+	google.golang.org/protobuf v1.26.0-rc.1 // indirect
+	gopkg.in/yaml.v2 v2.3.0 // indirect
+)
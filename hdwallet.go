@@ -0,0 +1,142 @@
+package sdk
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/XunleiBlockchain/tc-libs/accounts"
+	"github.com/XunleiBlockchain/tc-libs/common"
+	"github.com/XunleiBlockchain/tc-libs/crypto"
+	"github.com/tyler-smith/go-bip39"
+)
+
+var (
+	errNoMnemonic     = errors.New("hd wallet: no mnemonic imported")
+	errInvalidHDChild = errors.New("hd wallet: invalid child key, try the next index")
+	errHDSeedLoaded   = errors.New("hd wallet: a mnemonic is already imported, the derived addresses depend on it, pass overwrite to replace it")
+)
+
+// NewMnemonic generates a BIP-39 mnemonic with the given entropy bits (128~256, multiple of 32)
+func NewMnemonic(bits int) (string, error) {
+	entropy, err := bip39.NewEntropy(bits)
+	if err != nil {
+		return "", err
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+// hdWallet derives secp256k1 keys from a BIP-39 seed following BIP-32/BIP-44
+type hdWallet struct {
+	mu   sync.RWMutex
+	seed []byte
+	base accounts.DerivationPath
+}
+
+func newHDWallet(path string) (*hdWallet, error) {
+	base := accounts.DefaultRootDerivationPath
+	if path != "" {
+		var err error
+		if base, err = accounts.ParseDerivationPath(path); err != nil {
+			return nil, err
+		}
+	}
+	return &hdWallet{base: base}, nil
+}
+
+// setMnemonic loads the seed of mnemonic, replacing a loaded one only if overwrite
+func (w *hdWallet) setMnemonic(mnemonic, passphrase string, overwrite bool) error {
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, passphrase)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.seed != nil && !overwrite {
+		return errHDSeedLoaded
+	}
+	w.seed = seed
+	return nil
+}
+
+// path returns the derivation path of the index-th address under the base path
+func (w *hdWallet) path(index uint32) accounts.DerivationPath {
+	path := make(accounts.DerivationPath, len(w.base)+1)
+	copy(path, w.base)
+	path[len(w.base)] = index
+	return path
+}
+
+// derive returns the private key at the index-th path. Callers should Reset it after use.
+func (w *hdWallet) derive(index uint32) (crypto.PrivKey, error) {
+	if crypto.LocalAccountType() != crypto.CryptoTypeSecp256K1 {
+		return nil, fmt.Errorf("hd wallet: unsupported crypto type %s", crypto.LocalAccountType())
+	}
+	w.mu.RLock()
+	seed := w.seed
+	w.mu.RUnlock()
+	if seed == nil {
+		return nil, errNoMnemonic
+	}
+	key, chainCode := hdMasterKey(seed)
+	for _, n := range w.path(index) {
+		var err error
+		if key, chainCode, err = hdChildKey(key, chainCode, n); err != nil {
+			return nil, err
+		}
+	}
+	return crypto.GeneratePrivKeyFromSecret(common.LeftPadBytes(key, 32), crypto.CryptoTypeSecp256K1)
+}
+
+func (w *hdWallet) deriveAddress(index uint32) (common.Address, error) {
+	key, err := w.derive(index)
+	if err != nil {
+		return common.Address{}, err
+	}
+	defer key.Reset()
+	return crypto.PubkeyToAddress(key.PubKey()), nil
+}
+
+// ------------------------------- BIP-32 -------------------------------
+func hdMasterKey(seed []byte) ([]byte, []byte) {
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	return sum[:32], sum[32:]
+}
+
+func hdChildKey(key, chainCode []byte, index uint32) ([]byte, []byte, error) {
+	curve := crypto.S256()
+	data := make([]byte, 0, 37)
+	if index >= 0x80000000 {
+		data = append(data, 0x0)
+		data = append(data, common.LeftPadBytes(key, 32)...)
+	} else {
+		x, y := curve.ScalarBaseMult(common.LeftPadBytes(key, 32))
+		data = append(data, byte(0x2+y.Bit(0)))
+		data = append(data, common.LeftPadBytes(x.Bytes(), 32)...)
+	}
+	var seq [4]byte
+	binary.BigEndian.PutUint32(seq[:], index)
+	data = append(data, seq[:]...)
+
+	mac := hmac.New(sha512.New, chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	n := curve.Params().N
+	il := new(big.Int).SetBytes(sum[:32])
+	if il.Cmp(n) >= 0 {
+		return nil, nil, errInvalidHDChild
+	}
+	child := il.Add(il, new(big.Int).SetBytes(key))
+	child.Mod(child, n)
+	if child.Sign() == 0 {
+		return nil, nil, errInvalidHDChild
+	}
+	return common.LeftPadBytes(child.Bytes(), 32), sum[32:], nil
+}
@@ -0,0 +1,107 @@
+package sdk
+
+import (
+	"encoding/hex"
+	"strconv"
+	"testing"
+
+	"github.com/XunleiBlockchain/tc-libs/common"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// BIP-32 test vector 1
+func TestHDKeyVector1(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	steps := []struct {
+		index     uint32
+		chainCode string
+		key       string
+	}{
+		{0, "873dff81c02f525623fd1fe5167eac3a55a049de3d314bb42ee227ffed37d508", "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35"},
+		{0x80000000, "47fdacbd0f1097043b78c63c20c34ef4ed9a111d980047ad16282c7ae6236141", "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea"},
+		{1, "2a7857631386ba23dacac34180dd1983734e444fdbf774041578e9b6adb37c19", "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368"},
+		{0x80000002, "04466b9cc8e161e966409ca52986c584f07e9dc81f735db683c3ff6ec7b1503f", "cbce0d719ecf7431d88e6a89fa1483e02e35092af60c042b1df2ff59fa424dca"},
+		{2, "cfb71883f01676f587d023cc53a35bc7f88f724b1f8c2892ac1275ac822a3edd", "0f479245fb19a38a1954c5c7c0ebab2f9bdfd96a17563ef28a6a4b1a2a764ef4"},
+		{1000000000, "c783e67b921d2beb8f6b389cc646d7263b4145701dadd2161548a8b078e65e9e", "471b76e389e528d6de6d816857e012c5455051cad6660850e58372a6c3e6e7c8"},
+	}
+	key, chainCode := hdMasterKey(seed)
+	path := "m"
+	for i, step := range steps {
+		if i > 0 {
+			var err error
+			if key, chainCode, err = hdChildKey(key, chainCode, step.index); err != nil {
+				t.Fatalf("%s/%d: %v", path, step.index, err)
+			}
+			path += "/" + hdIndexString(step.index)
+		}
+		if got := hex.EncodeToString(chainCode); got != step.chainCode {
+			t.Errorf("%s chain code = %s, want %s", path, got, step.chainCode)
+		}
+		if got := hex.EncodeToString(key); got != step.key {
+			t.Errorf("%s key = %s, want %s", path, got, step.key)
+		}
+	}
+}
+
+func hdIndexString(index uint32) string {
+	if index >= 0x80000000 {
+		return strconv.FormatUint(uint64(index-0x80000000), 10) + "'"
+	}
+	return strconv.FormatUint(uint64(index), 10)
+}
+
+// BIP-39 test vectors and the widely published m/44'/60'/0'/0/0 address of the
+// "abandon ... about" mnemonic
+func TestHDMnemonicAddress(t *testing.T) {
+	const mnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	seeds := map[string]string{
+		"":       "5eb00bbddcf069084889a8ab9155568165f5c453ccb85e70811aaed6f6da5fc19a5ac40b389cd370d086206dec8aa6c43daea6690f20ad3d8d48b2d2ce9e38e4",
+		"TREZOR": "c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+	}
+	for passphrase, want := range seeds {
+		seed, err := bip39.NewSeedWithErrorChecking(mnemonic, passphrase)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(seed); got != want {
+			t.Errorf("seed(%q) = %s, want %s", passphrase, got, want)
+		}
+	}
+
+	w, err := newHDWallet("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.setMnemonic(mnemonic, "", false); err != nil {
+		t.Fatal(err)
+	}
+	addr, err := w.deriveAddress(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := common.HexToAddress("0x9858EfFD232B4033E47d90003D41EC34EcaEda94"); addr != want {
+		t.Errorf("m/44'/60'/0'/0/0 = %s, want %s", addr.Hex(), want.Hex())
+	}
+}
+
+func TestHDMnemonicOverwrite(t *testing.T) {
+	w, _ := newHDWallet("")
+	first, _ := NewMnemonic(128)
+	second, _ := NewMnemonic(128)
+	if err := w.setMnemonic(first, "", false); err != nil {
+		t.Fatal(err)
+	}
+	addr, _ := w.deriveAddress(0)
+	if err := w.setMnemonic(second, "", false); err != errHDSeedLoaded {
+		t.Fatalf("replace without overwrite: err = %v, want %v", err, errHDSeedLoaded)
+	}
+	if got, _ := w.deriveAddress(0); got != addr {
+		t.Fatal("seed replaced without overwrite")
+	}
+	if err := w.setMnemonic(second, "", true); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := w.deriveAddress(0); got == addr {
+		t.Fatal("seed not replaced with overwrite")
+	}
+}
@@ -2,6 +2,7 @@ package sdk
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"fmt"
 	"io/ioutil"
@@ -31,7 +32,7 @@ var (
 )
 
 // ------------------------------ http cli ------------------------------
-func httpGetWithLongConn(url string) ([]byte, error) {
+func httpGetWithLongConn(log Logger, url string) ([]byte, error) {
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("httpGet req get error: %s", err.Error())
@@ -46,53 +47,57 @@ func httpGetWithLongConn(url string) ([]byte, error) {
 		return nil, fmt.Errorf("httpGet io read error: %s", err.Error())
 	}
 	if resp.StatusCode != 200 {
-		sdklog.Error("httpGetWithLongConn HttpReqError, op: get", "url", url, "statusCode", resp.StatusCode)
+		log.Error("httpGetWithLongConn HttpReqError, op: get", "url", url, "statusCode", resp.StatusCode)
 		return nil, fmt.Errorf("httpGet status code not 200, url(%s) status code %d", url, resp.StatusCode)
 	}
 	if body == nil || len(body) == 0 {
 		return nil, fmt.Errorf("httpGet resp body nil")
 	}
-	sdklog.Info("httpGetWithLongConn success", "url", url, "resp", string(body))
+	log.Debug("httpGetWithLongConn success", "url", url, "resp", Payload(body))
 	return body, nil
 }
 
-func httpPostWithLongConn(url string, host string, contentType string, data []byte) ([]byte, error) {
-	req, err := http.NewRequest("POST", url, bytes.NewReader(data))
+func httpPostWithLongConn(ctx context.Context, log Logger, url string, host string, header http.Header, data []byte) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(data))
 	if err != nil {
-		return nil, fmt.Errorf("httpPost req post error: %s", err.Error())
+		return nil, 0, fmt.Errorf("httpPost req post error: %s", err.Error())
 	}
 	if host != "" {
 		req.Host = host
 	}
-	if contentType != "" {
-		req.Header.Add("Content-Type", contentType)
-	} else {
-		req.Header.Add("Content-Type", "application/json")
+	for k, v := range header {
+		req.Header[k] = v
 	}
-	req.Header.Add("User-Agent", "User-Agent: Mozilla/5.0 (Macintosh; Intel Mac OS X 10_7_0) AppleWebKit/535.11 (KHTML, like Gecko) Chrome/17.0.963.56 Safari/535.11")
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", "User-Agent: Mozilla/5.0 (Macintosh; Intel Mac OS X 10_7_0) AppleWebKit/535.11 (KHTML, like Gecko) Chrome/17.0.963.56 Safari/535.11")
+	}
+	InjectTraceContext(ctx, req.Header)
 	resp, err := gHTTPClient.Do(req)
 	if resp != nil && resp.Body != nil {
 		defer resp.Body.Close()
 	}
 	if err != nil {
-		return nil, fmt.Errorf("httpPost client do error: %s", err.Error())
+		return nil, 0, fmt.Errorf("httpPost client do error: %s", err.Error())
 	}
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("HttpsPost io read error: %s, body[%d]:%s", err.Error(), len(body), string(body))
+		return nil, resp.StatusCode, fmt.Errorf("HttpsPost io read error: %s, body[%d]", err.Error(), len(body))
 	}
 	if resp.StatusCode != 200 {
-		sdklog.Error("httpPostWithLongConn HttpReqError, op: post", "url", url, "statusCode", resp.StatusCode)
-		return nil, fmt.Errorf("HttpsPost status code not 200, url(%s) body(%s) status code %d", url, data, resp.StatusCode)
+		log.Error("httpPostWithLongConn HttpReqError, op: post", "url", url, "statusCode", resp.StatusCode)
+		return nil, resp.StatusCode, fmt.Errorf("HttpsPost status code not 200, url(%s) status code %d", url, resp.StatusCode)
 	}
 	if body == nil || len(body) == 0 {
-		return nil, fmt.Errorf("HttpsPost resp body nil")
+		return nil, resp.StatusCode, fmt.Errorf("HttpsPost resp body nil")
 	}
-	sdklog.Info("httpPostWithLongConn success", "url", url, "resp", string(body))
-	return body, nil
+	log.Debug("httpPostWithLongConn success", "url", url, "resp", Payload(body))
+	return body, resp.StatusCode, nil
 }
 
-func httpGet(url string) ([]byte, error) {
+func httpGet(log Logger, url string) ([]byte, error) {
 	tr := &http.Transport{
 		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
 	}
@@ -111,17 +116,17 @@ func httpGet(url string) ([]byte, error) {
 		return nil, fmt.Errorf("httpGet io read error: %s", err.Error())
 	}
 	if resp.StatusCode != 200 {
-		sdklog.Error("httpGet HttpReqError, op: get", "url", url, "statusCode", resp.StatusCode)
+		log.Error("httpGet HttpReqError, op: get", "url", url, "statusCode", resp.StatusCode)
 		return nil, fmt.Errorf("httpGet status code not 200, url(%s) status code %d", url, resp.StatusCode)
 	}
 	if body == nil || len(body) == 0 {
 		return nil, fmt.Errorf("httpGet resp body nil")
 	}
-	sdklog.Info("httpGet success", "url", url, "resp", string(body))
+	log.Debug("httpGet success", "url", url, "resp", Payload(body))
 	return body, nil
 }
 
-func httpPost(url string, host string, contentType string, data []byte) ([]byte, error) {
+func httpPost(log Logger, url string, host string, contentType string, data []byte) ([]byte, error) {
 	tr := &http.Transport{
 		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
 	}
@@ -146,15 +151,15 @@ func httpPost(url string, host string, contentType string, data []byte) ([]byte,
 	defer resp.Body.Close()
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("httpPost io read error: %s, body[%d]:%s", err.Error(), len(body), string(body))
+		return nil, fmt.Errorf("httpPost io read error: %s, body[%d]", err.Error(), len(body))
 	}
 	if resp.StatusCode != 200 {
-		sdklog.Error("httpPost HttpReqError, op: post", "url", url, "statusCode", resp.StatusCode)
-		return nil, fmt.Errorf("httpPost status code not 200, url(%s) body(%s) status code %d", url, data, resp.StatusCode)
+		log.Error("httpPost HttpReqError, op: post", "url", url, "statusCode", resp.StatusCode)
+		return nil, fmt.Errorf("httpPost status code not 200, url(%s) status code %d", url, resp.StatusCode)
 	}
 	if body == nil || len(body) == 0 {
 		return nil, fmt.Errorf("httpPost resp body nil")
 	}
-	sdklog.Info("httpPost success", "url", url, "resp", string(body))
+	log.Debug("httpPost success", "url", url, "resp", Payload(body))
 	return body, nil
 }
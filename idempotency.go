@@ -0,0 +1,364 @@
+package sdk
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/XunleiBlockchain/tc-libs/common"
+)
+
+const (
+	defaultIdempotencyTTL = 24 * time.Hour
+	// idempotencyClaimTTL bounds a claim whose request never completes, e.g. on a crash
+	idempotencyClaimTTL = 5 * time.Minute
+	// prepayIDKeyPrefix scopes ContractExtension.PrepayID keys apart from explicit ones
+	prepayIDKeyPrefix = "prepay_id:"
+)
+
+var (
+	// errIdempotencyInFlight is returned for a key whose first request is still sending
+	errIdempotencyInFlight = errors.New("a request with the same idempotency key is in progress")
+	// errIdempotencyMismatch is returned for a key claimed by a request for another transaction
+	errIdempotencyMismatch = errors.New("idempotency key reused with different params")
+)
+
+// IdempotencyStore maps idempotency keys to the hash of the first transaction sent
+// with them. Implementations backed by a shared store let replicas dedupe together.
+type IdempotencyStore interface {
+	// Claim reserves key for ttl for the request of fingerprint. If the key is taken
+	// by a request of the same fingerprint it returns claimed false and the hash
+	// stored by Complete, empty while the first request is in flight; if taken by
+	// another fingerprint it returns an error.
+	Claim(key, fingerprint string, ttl time.Duration) (hash string, claimed bool, err error)
+	// Complete stores hash for key, it is returned to duplicates for ttl
+	Complete(key, hash string, ttl time.Duration) error
+	// Release drops the claim of a request that sent nothing, so key can be retried
+	Release(key string) error
+}
+
+type (
+	idempotencyKey   struct{}
+	idempotencyScope struct{}
+)
+
+// ContextWithIdempotencyKey returns a copy of ctx carrying key: SendTransaction and
+// SendContractTransaction called under it send at most one transaction per key and
+// return its hash to the later calls. An empty key removes the key of ctx.
+func ContextWithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+// IdempotencyKeyFromContext returns the idempotency key carried by ctx
+func IdempotencyKeyFromContext(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(idempotencyKey{}).(string)
+	return key, ok && key != ""
+}
+
+// ContextWithIdempotencyScope returns a copy of ctx whose idempotency keys, and
+// prepay_id keys, are kept apart from those of other scopes, e.g. the callers of a
+// SDK shared by several clients
+func ContextWithIdempotencyScope(ctx context.Context, scope string) context.Context {
+	return context.WithValue(ctx, idempotencyScope{}, scope)
+}
+
+// MemoryIdempotencyStore is the in process IdempotencyStore used when Config.Idempotency is nil
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	entries idempotencyEntries
+}
+
+// NewMemoryIdempotencyStore returns an empty MemoryIdempotencyStore
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{entries: idempotencyEntries{m: make(map[string]idempotencyEntry)}}
+}
+
+// Claim implements IdempotencyStore
+func (m *MemoryIdempotencyStore) Claim(key, fingerprint string, ttl time.Duration) (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.entries.claim(key, fingerprint, ttl)
+}
+
+// Complete implements IdempotencyStore
+func (m *MemoryIdempotencyStore) Complete(key, hash string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries.complete(key, hash, ttl)
+	return nil
+}
+
+// Release implements IdempotencyStore
+func (m *MemoryIdempotencyStore) Release(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries.m, key)
+	return nil
+}
+
+// FileIdempotencyStore is an IdempotencyStore appending JSON lines to a file, synced
+// on every change, so that the keys survive a restart. The file is compacted to the
+// live keys on open and as it grows. It does not dedupe across hosts.
+type FileIdempotencyStore struct {
+	path string
+	log  Logger
+
+	mu      sync.Mutex
+	f       *os.File
+	entries idempotencyEntries
+	lines   int // 文件中的记录数
+}
+
+// idempotencyRecord is a line of the FileIdempotencyStore file
+type idempotencyRecord struct {
+	Key         string `json:"key"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+	Hash        string `json:"hash,omitempty"`
+	Expires     int64  `json:"expires"` // Unix秒 0表示已释放
+}
+
+// NewFileIdempotencyStore opens or creates the store file at path, log receives the
+// compaction failures
+func NewFileIdempotencyStore(path string, log Logger) (*FileIdempotencyStore, error) {
+	if log == nil {
+		log = NopLogger()
+	}
+	s := &FileIdempotencyStore{path: path, log: log, entries: idempotencyEntries{m: make(map[string]idempotencyEntry)}}
+	f, err := os.Open(path)
+	switch {
+	case err == nil:
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var r idempotencyRecord
+			// a line cut short by a crash is skipped
+			if json.Unmarshal(scanner.Bytes(), &r) != nil {
+				continue
+			}
+			if r.Expires == 0 {
+				delete(s.entries.m, r.Key)
+				continue
+			}
+			s.entries.m[r.Key] = idempotencyEntry{fingerprint: r.Fingerprint, hash: r.Hash, expires: time.Unix(r.Expires, 0)}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("idempotency store %s: %v", path, err)
+		}
+	case !os.IsNotExist(err):
+		return nil, err
+	}
+	if err := s.compact(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Claim implements IdempotencyStore
+func (s *FileIdempotencyStore) Claim(key, fingerprint string, ttl time.Duration) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	hash, claimed, err := s.entries.claim(key, fingerprint, ttl)
+	if !claimed {
+		return hash, claimed, err
+	}
+	if err := s.put(key); err != nil {
+		delete(s.entries.m, key)
+		return "", false, err
+	}
+	return "", true, nil
+}
+
+// Complete implements IdempotencyStore
+func (s *FileIdempotencyStore) Complete(key, hash string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries.complete(key, hash, ttl)
+	return s.put(key)
+}
+
+// Release implements IdempotencyStore
+func (s *FileIdempotencyStore) Release(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries.m, key)
+	return s.put(key)
+}
+
+// put appends the entry of key, a release if there is none, s.mu must be held
+func (s *FileIdempotencyStore) put(key string) error {
+	if s.f == nil {
+		return fmt.Errorf("idempotency store %s: closed", s.path)
+	}
+	r := idempotencyRecord{Key: key}
+	if e, ok := s.entries.m[key]; ok {
+		r = idempotencyRecord{Key: key, Fingerprint: e.fingerprint, Hash: e.hash, Expires: e.expires.Unix()}
+	}
+	data, err := json.Marshal(&r)
+	if err != nil {
+		return err
+	}
+	if _, err := s.f.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	if err := s.f.Sync(); err != nil {
+		return err
+	}
+	if s.lines++; s.lines > len(s.entries.m)+journalCompactLines {
+		// the record is written, a failed compaction is retried after as many records again
+		if err := s.compact(); err != nil {
+			s.log.Warn("idempotency store compact fail", "path", s.path, "err", err)
+			s.lines = len(s.entries.m)
+		}
+	}
+	return nil
+}
+
+// compact rewrites the file with the live entries, s.mu must be held
+func (s *FileIdempotencyStore) compact() error {
+	s.entries.prune(time.Now())
+	f, replaced, err := rewriteFile(s.path, func(w *bufio.Writer) {
+		for key, e := range s.entries.m {
+			data, _ := json.Marshal(&idempotencyRecord{Key: key, Fingerprint: e.fingerprint, Hash: e.hash, Expires: e.expires.Unix()})
+			w.Write(append(data, '\n'))
+		}
+	})
+	if !replaced {
+		return err
+	}
+	if s.f != nil {
+		s.f.Close()
+	}
+	s.f = f
+	if err != nil {
+		return err
+	}
+	s.lines = len(s.entries.m)
+	return nil
+}
+
+// Close closes the file, later changes fail
+func (s *FileIdempotencyStore) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f != nil {
+		s.f.Close()
+		s.f = nil
+	}
+}
+
+type idempotencyEntry struct {
+	fingerprint string
+	hash        string // 为空表示首个请求处理中
+	expires     time.Time
+}
+
+// idempotencyEntries holds the keys of the stores, expired ones are pruned every minute
+type idempotencyEntries struct {
+	m      map[string]idempotencyEntry
+	pruned time.Time
+}
+
+func (es *idempotencyEntries) prune(now time.Time) {
+	for k, e := range es.m {
+		if now.After(e.expires) {
+			delete(es.m, k)
+		}
+	}
+	es.pruned = now
+}
+
+func (es *idempotencyEntries) claim(key, fingerprint string, ttl time.Duration) (string, bool, error) {
+	now := time.Now()
+	if now.Sub(es.pruned) > time.Minute {
+		es.prune(now)
+	}
+	if e, ok := es.m[key]; ok && now.Before(e.expires) {
+		// a completion outliving its claim has no fingerprint
+		if e.fingerprint != "" && e.fingerprint != fingerprint {
+			return "", false, errIdempotencyMismatch
+		}
+		return e.hash, false, nil
+	}
+	es.m[key] = idempotencyEntry{fingerprint: fingerprint, expires: now.Add(ttl)}
+	return "", true, nil
+}
+
+func (es *idempotencyEntries) complete(key, hash string, ttl time.Duration) {
+	e := es.m[key]
+	e.hash, e.expires = hash, time.Now().Add(ttl)
+	es.m[key] = e
+}
+
+// idempotencyClaim is the claim of a send on its key, broadcast stores the hash of
+// the transaction before sending it and records whether BaaS refused it
+type idempotencyClaim struct {
+	key     string
+	hash    common.Hash // 已存储的交易哈希 未存储为零值
+	refused bool        // BaaS明确拒绝 交易未发送
+}
+
+type idempotencyClaimKey struct{}
+
+// claimIdempotency claims the idempotency key of ctx, or else the prepay_id of ext
+// for the account sending. It returns the hash of the transaction already sent with
+// the key as dup, or the claim to finish once the send is done, both nil without a key.
+func (sdk *SDKImpl) claimIdempotency(ctx context.Context, args *SendTxArgs, ext *ContractExtension) (claim *idempotencyClaim, dup interface{}, xerr *Error) {
+	key, ok := IdempotencyKeyFromContext(ctx)
+	if !ok && ext != nil && ext.PrepayID != "" {
+		// prepay ids are unique per merchant account only
+		key, ok = prepayIDKeyPrefix+strings.ToLower(args.From.Hex())+"/"+ext.PrepayID, true
+	}
+	if !ok {
+		return nil, nil, nil
+	}
+	if scope, _ := ctx.Value(idempotencyScope{}).(string); scope != "" {
+		key = scope + "/" + key
+	}
+	hash, claimed, err := sdk.idempotency.Claim(key, idempotencyFingerprint(args, ext), idempotencyClaimTTL)
+	switch {
+	case err != nil:
+		return nil, nil, ErrIdempotency.Join(err)
+	case !claimed && hash == "":
+		return nil, nil, ErrIdempotency.Join(errIdempotencyInFlight)
+	case !claimed:
+		sdk.log.Info("idempotent duplicate", "key", key, "hash", hash)
+		return nil, hash, nil
+	}
+	return &idempotencyClaim{key: key}, nil, nil
+}
+
+// finishIdempotency releases the key of a send that surely did not reach BaaS, the
+// hash of one that may have is kept as broadcast stored it
+func (sdk *SDKImpl) finishIdempotency(claim *idempotencyClaim) {
+	if claim.hash != (common.Hash{}) && !claim.refused {
+		return
+	}
+	if err := sdk.idempotency.Release(claim.key); err != nil {
+		sdk.log.Error("idempotency store fail", "key", claim.key, "err", err)
+	}
+}
+
+// idempotencyFingerprint identifies the transaction a send asks for, so that a key
+// reused for another one is refused. The password and the sign of ext are left out.
+func idempotencyFingerprint(args *SendTxArgs, ext *ContractExtension) string {
+	v := struct {
+		Tx  *SendTxArgs        `json:"tx"`
+		Ext *ContractExtension `json:"ext,omitempty"`
+	}{Tx: args}
+	if ext != nil {
+		e := *ext
+		e.Sign = ""
+		v.Ext = &e
+	}
+	data, _ := json.Marshal(&v)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
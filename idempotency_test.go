@@ -0,0 +1,89 @@
+package sdk
+
+import (
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/XunleiBlockchain/tc-libs/common"
+)
+
+func checkClaim(t *testing.T, s IdempotencyStore, key, fingerprint string, wantHash string, wantClaimed bool) {
+	t.Helper()
+	hash, claimed, err := s.Claim(key, fingerprint, time.Minute)
+	if err != nil {
+		t.Fatalf("claim %s: %v", key, err)
+	}
+	if hash != wantHash || claimed != wantClaimed {
+		t.Fatalf("claim %s = %q %v, want %q %v", key, hash, claimed, wantHash, wantClaimed)
+	}
+}
+
+func testIdempotencyStore(t *testing.T, s IdempotencyStore) {
+	checkClaim(t, s, "k1", "fp1", "", true)
+	checkClaim(t, s, "k1", "fp1", "", false)
+	if _, _, err := s.Claim("k1", "fp2", time.Minute); err != errIdempotencyMismatch {
+		t.Errorf("claim with another fingerprint: err = %v", err)
+	}
+	if err := s.Complete("k1", "0x01", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	checkClaim(t, s, "k1", "fp1", "0x01", false)
+
+	checkClaim(t, s, "k2", "fp1", "", true)
+	if err := s.Release("k2"); err != nil {
+		t.Fatal(err)
+	}
+	checkClaim(t, s, "k2", "fp2", "", true)
+	if _, _, err := s.Claim("k3", "fp1", -time.Second); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMemoryIdempotencyStore(t *testing.T) {
+	testIdempotencyStore(t, NewMemoryIdempotencyStore())
+}
+
+func TestFileIdempotencyStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "idempotency.log")
+	s, err := NewFileIdempotencyStore(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testIdempotencyStore(t, s)
+	s.Close()
+	if _, _, err := s.Claim("k4", "fp1", time.Minute); err == nil {
+		t.Error("claim after close")
+	}
+
+	// the keys survive a restart, the released and expired ones do not
+	s, err = NewFileIdempotencyStore(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if s.lines != 2 {
+		t.Errorf("lines = %d, want compacted to k1 k2", s.lines)
+	}
+	checkClaim(t, s, "k1", "fp1", "0x01", false)
+	checkClaim(t, s, "k2", "fp2", "", false)
+	checkClaim(t, s, "k3", "fp2", "", true)
+	checkClaim(t, s, "k4", "fp1", "", true)
+}
+
+func TestIdempotencyFingerprint(t *testing.T) {
+	args := &SendTxArgs{From: common.HexToAddress("0x622bc0938fae8b028fcf124f9ba8580719009fdc"), Value: big.NewInt(1)}
+	ext := &ContractExtension{PrepayID: "p1", Sign: "s1"}
+	fp := idempotencyFingerprint(args, ext)
+	signed := *ext
+	signed.Sign = "s2"
+	if idempotencyFingerprint(args, &signed) != fp {
+		t.Error("sign changes the fingerprint")
+	}
+	other := *args
+	other.Value = nil
+	if idempotencyFingerprint(&other, ext) == fp || idempotencyFingerprint(args, nil) == fp {
+		t.Error("another transaction has the same fingerprint")
+	}
+}
@@ -16,6 +16,18 @@ type SDK interface {
 	Call(params interface{}) (interface{}, *Error)
 	SignTx(params interface{}) (interface{}, *Error)
 	SendRawTransaction(params interface{}) (interface{}, *Error)
+	NewMnemonic(params interface{}) (interface{}, *Error)
+	ImportMnemonic(params interface{}) (interface{}, *Error)
+	DeriveAddress(params interface{}) (interface{}, *Error)
+	DeriveAccount(params interface{}) (interface{}, *Error)
+	ImportRawKey(params interface{}) (interface{}, *Error)
+	ImportKeystore(params interface{}) (interface{}, *Error)
+	ExportKeystore(params interface{}) (interface{}, *Error)
+	UpdatePassword(params interface{}) (interface{}, *Error)
+	DeleteAccount(params interface{}) (interface{}, *Error)
+	UnlockAccount(params interface{}) (interface{}, *Error)
+	LockAccount(params interface{}) (interface{}, *Error)
+	AccountStatus(params interface{}) (interface{}, *Error)
 }
 
 var _ SDK = &SDKImpl{}
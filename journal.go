@@ -0,0 +1,399 @@
+package sdk
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/XunleiBlockchain/baas-sdk-go/types"
+	"github.com/XunleiBlockchain/tc-libs/accounts"
+	"github.com/XunleiBlockchain/tc-libs/common"
+)
+
+// Journal statuses besides TxPending TxSuccess TxFailed TxDropped
+const (
+	JournalSigned   = "signed"   // 已签名 未确认BaaS收到
+	JournalRejected = "rejected" // BaaS拒绝广播
+)
+
+// JournalEntry is the record of a transaction signed by the SDK
+type JournalEntry struct {
+	Hash    common.Hash    `json:"hash"`
+	From    common.Address `json:"from"`
+	Nonce   uint64         `json:"nonce"`
+	Raw     string         `json:"raw"`           // 0x十六进制的已签名交易
+	Ext     interface{}    `json:"ext,omitempty"` // 合约交易的扩展参数
+	Status  string         `json:"status"`
+	Updated int64          `json:"updated"`
+}
+
+// finished reports whether the entry needs no more tracking
+func (e *JournalEntry) finished() bool {
+	switch e.Status {
+	case JournalSigned, TxPending:
+		return false
+	}
+	return true
+}
+
+// Journal durably records the transactions the SDK signs, before they are broadcast,
+// and their status changes. NewSDK rebroadcasts and tracks the unfinished ones.
+type Journal interface {
+	// Put records e, replacing the entry of the same hash
+	Put(e *JournalEntry) error
+	// Unfinished returns the entries in status signed or pending
+	Unfinished() ([]*JournalEntry, error)
+}
+
+// FileJournal is a Journal appending JSON lines to a file, synced on every Put.
+// The file is compacted to the unfinished entries on open and as it grows.
+type FileJournal struct {
+	path string
+	log  Logger
+
+	mu         sync.Mutex
+	f          *os.File
+	unfinished map[common.Hash]*JournalEntry
+	lines      int // 文件中的记录数
+}
+
+// journalCompactLines is the number of records over the unfinished ones that triggers compaction
+const journalCompactLines = 4096
+
+// NewFileJournal opens or creates the journal file at path, log receives the
+// compaction failures of Put
+func NewFileJournal(path string, log Logger) (*FileJournal, error) {
+	if log == nil {
+		log = NopLogger()
+	}
+	j := &FileJournal{path: path, log: log, unfinished: make(map[common.Hash]*JournalEntry)}
+	f, err := os.Open(path)
+	switch {
+	case err == nil:
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			var e JournalEntry
+			// a line cut short by a crash is skipped
+			if json.Unmarshal(scanner.Bytes(), &e) != nil {
+				continue
+			}
+			j.apply(&e)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("journal %s: %v", path, err)
+		}
+	case !os.IsNotExist(err):
+		return nil, err
+	}
+	if err := j.compact(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+func (j *FileJournal) apply(e *JournalEntry) {
+	if e.finished() {
+		delete(j.unfinished, e.Hash)
+		return
+	}
+	entry := *e
+	j.unfinished[e.Hash] = &entry
+}
+
+// Put implements Journal
+func (j *FileJournal) Put(e *JournalEntry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.f == nil {
+		return fmt.Errorf("journal %s: closed", j.path)
+	}
+	if _, err := j.f.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	if err := j.f.Sync(); err != nil {
+		return err
+	}
+	j.apply(e)
+	if j.lines++; j.lines > len(j.unfinished)+journalCompactLines {
+		// e is recorded, a failed compaction is retried after as many records again
+		if err := j.compact(); err != nil {
+			j.log.Warn("journal compact fail", "path", j.path, "err", err)
+			j.lines = len(j.unfinished)
+		}
+	}
+	return nil
+}
+
+// Unfinished implements Journal
+func (j *FileJournal) Unfinished() ([]*JournalEntry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	res := make([]*JournalEntry, 0, len(j.unfinished))
+	for _, e := range j.unfinished {
+		entry := *e
+		res = append(res, &entry)
+	}
+	return res, nil
+}
+
+// compact rewrites the file with the unfinished entries, j.mu must be held. On
+// failure before the rename the current file stays in use.
+func (j *FileJournal) compact() error {
+	f, replaced, err := rewriteFile(j.path, func(w *bufio.Writer) {
+		for _, e := range j.unfinished {
+			data, _ := json.Marshal(e)
+			w.Write(append(data, '\n'))
+		}
+	})
+	if !replaced {
+		return err
+	}
+	if j.f != nil {
+		j.f.Close()
+	}
+	// the previous file is replaced, appending to it would lose the records
+	j.f = f
+	if err != nil {
+		return err
+	}
+	j.lines = len(j.unfinished)
+	return nil
+}
+
+// rewriteFile replaces the file at path with what write writes, synced to disk, and
+// opens it for appending. replaced reports whether path was replaced, if not the
+// previous file is intact.
+func rewriteFile(path string, write func(w *bufio.Writer)) (f *os.File, replaced bool, err error) {
+	tmp, err := os.Create(path + ".tmp")
+	if err != nil {
+		return nil, false, err
+	}
+	w := bufio.NewWriter(tmp)
+	write(w)
+	if err = w.Flush(); err == nil {
+		err = tmp.Sync()
+	}
+	tmp.Close()
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return nil, false, err
+	}
+	if dir, err := os.Open(filepath.Dir(path)); err == nil {
+		dir.Sync()
+		dir.Close()
+	}
+	f, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	return f, true, err
+}
+
+// Close closes the file, later Puts fail
+func (j *FileJournal) Close() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.f != nil {
+		j.f.Close()
+		j.f = nil
+	}
+}
+
+// txJournal keeps the status of the journaled transactions of a SDK so that
+// updates only move forward: signed, then pending or rejected, then final.
+type txJournal struct {
+	j   Journal
+	log Logger
+
+	mu      sync.Mutex
+	entries map[common.Hash]*JournalEntry // 未结束的交易
+}
+
+func newTxJournal(j Journal, log Logger) *txJournal {
+	return &txJournal{j: j, log: log, entries: make(map[common.Hash]*JournalEntry)}
+}
+
+// add records a signed transaction before it is broadcast
+func (t *txJournal) add(e *JournalEntry) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	e.Updated = time.Now().Unix()
+	if err := t.j.Put(e); err != nil {
+		return err
+	}
+	t.entries[e.Hash] = e
+	return nil
+}
+
+// advance moves the transaction hash to status, a finished or unknown one is left alone
+func (t *txJournal) advance(hash common.Hash, status string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	e, ok := t.entries[hash]
+	if !ok || (status == TxPending && e.Status == TxPending) {
+		return
+	}
+	next := *e
+	next.Status = status
+	next.Updated = time.Now().Unix()
+	if err := t.j.Put(&next); err != nil {
+		t.log.Error("journal put fail", "hash", hash, "status", status, "err", err)
+		return
+	}
+	if next.finished() {
+		delete(t.entries, hash)
+	} else {
+		t.entries[hash] = &next
+	}
+}
+
+// broadcast sends the signed transaction raw of args, as a contract transaction
+// if ext is not nil, journaling it first with Config.Journal. A transaction that
+// cannot be journaled is not sent.
+func (sdk *SDKImpl) broadcast(ctx context.Context, signed accounts.SingerTx, args *SendTxArgs, raw string, ext interface{}) (res interface{}, xerr *Error) {
+	send := func() (interface{}, *Error) {
+		if ext != nil {
+			return sdk.c.sendContractTransaction(ctx, raw, ext)
+		}
+		return sdk.c.sendTransaction(ctx, raw)
+	}
+	tx, ok := signed.(types.Tx)
+	if claim, _ := ctx.Value(idempotencyClaimKey{}).(*idempotencyClaim); claim != nil && ok {
+		// stored before the broadcast, so a crash after it leaves the key answering
+		// with this transaction rather than letting the retry send another
+		if err := sdk.idempotency.Complete(claim.key, tx.Hash().Hex(), sdk.idempotencyTTL); err != nil {
+			return common.Hash{}, ErrIdempotency.Join(err)
+		}
+		claim.hash = tx.Hash()
+		defer func() { claim.refused = xerr != nil && refused(xerr) }()
+	}
+	if sdk.journal == nil || !ok {
+		return send()
+	}
+	hash := tx.Hash()
+	if err := sdk.journal.add(&JournalEntry{Hash: hash, From: args.From, Nonce: *args.Nonce, Raw: raw, Ext: ext, Status: JournalSigned}); err != nil {
+		return common.Hash{}, ErrJournal.Join(err)
+	}
+	res, xerr = send()
+	switch {
+	case xerr.Code == 0:
+		sdk.journal.advance(hash, TxPending)
+	case !refused(xerr):
+		// BaaS may have received it, it stays signed and is rebroadcast on recovery
+	default:
+		sdk.journal.advance(hash, JournalRejected)
+	}
+	return res, xerr
+}
+
+// refused reports whether BaaS answered a send with an error, so the transaction
+// was not broadcast. A transport error leaves it unknown.
+func refused(xerr *Error) bool {
+	switch xerr.Code {
+	case 0, ErrRpcSendTransaction.Code, ErrRpcSendContractTransaction.Code:
+		return false
+	}
+	return true
+}
+
+// trackJournal follows the journaled transactions to their final status through a
+// pendingTxStatus subscription, until Close
+func (sdk *SDKImpl) trackJournal() {
+	for {
+		sub := &Subscription{ID: "journal", Kind: SubPendingTxStatus, ch: make(chan interface{}, subscriptionBuffer), w: sdk.watcher}
+		if sdk.watcher.subscribe(sub) != nil {
+			return
+		}
+		sdk.journal.mu.Lock()
+		for hash := range sdk.journal.entries {
+			sdk.watcher.track(hash)
+		}
+		sdk.journal.mu.Unlock()
+		for v := range sub.C() {
+			if st, ok := v.(*TxStatus); ok && st.Status != TxPending {
+				sdk.journal.advance(st.Hash, st.Status)
+			}
+		}
+		if err := sub.Err(); err == errSubscriptionClosed {
+			return
+		}
+		sdk.log.Warn("journal tracking restarts", "err", sub.Err())
+	}
+}
+
+// recoverJournal rebroadcasts the unfinished transactions of the journal in nonce
+// order per account. One mined meanwhile gets its final status. One BaaS refuses
+// whose nonce BaaS has counted is tracked until mined or dropped, else it is
+// rejected, leaving a nonce gap the later transactions of the account wait on.
+func (sdk *SDKImpl) recoverJournal(ctx context.Context) error {
+	entries, err := sdk.journal.j.Unfinished()
+	if err != nil {
+		return err
+	}
+	sort.Slice(entries, func(a, b int) bool {
+		if entries[a].From != entries[b].From {
+			return entries[a].From.Hex() < entries[b].From.Hex()
+		}
+		return entries[a].Nonce < entries[b].Nonce
+	})
+	nonces := make(map[common.Address]uint64)
+	for _, e := range entries {
+		sdk.journal.entries[e.Hash] = e
+		receipt, xerr := sdk.c.getTransactionReceipt(ctx, e.Hash.Hex())
+		if xerr.Code != 0 {
+			sdk.log.Warn("journal recover receipt fail", "hash", e.Hash, "err", xerr)
+			continue
+		}
+		if r, ok := receipt.(map[string]interface{}); ok {
+			status := TxFailed
+			if s, _ := r["status"].(string); s == "0x1" {
+				status = TxSuccess
+			}
+			sdk.journal.advance(e.Hash, status)
+			continue
+		}
+		if _, ok := nonces[e.From]; !ok {
+			nonce, xerr := sdk.c.getNonce(ctx, e.From.Hex())
+			if xerr.Code != 0 {
+				sdk.log.Warn("journal recover nonce fail", "from", e.From, "err", xerr)
+				continue
+			}
+			nonces[e.From] = nonce
+		}
+		if e.Ext != nil {
+			_, xerr = sdk.c.sendContractTransaction(ctx, e.Raw, e.Ext)
+		} else {
+			_, xerr = sdk.c.sendTransaction(ctx, e.Raw)
+		}
+		switch {
+		case xerr.Code == 0:
+			sdk.log.Info("journal rebroadcast", "hash", e.Hash, "from", e.From, "nonce", e.Nonce)
+			sdk.journal.advance(e.Hash, TxPending)
+			if e.Nonce >= nonces[e.From] {
+				nonces[e.From] = e.Nonce + 1
+			}
+		case xerr.Code == ErrRpcSendTransaction.Code || xerr.Code == ErrRpcSendContractTransaction.Code:
+			sdk.log.Warn("journal rebroadcast fail", "hash", e.Hash, "err", xerr)
+		case e.Nonce < nonces[e.From]:
+			// known to BaaS or replaced, the receipt or the pending timeout decides
+			sdk.log.Info("journal rebroadcast refused, nonce used", "hash", e.Hash, "nonce", e.Nonce, "err", xerr)
+			sdk.journal.advance(e.Hash, TxPending)
+		default:
+			sdk.log.Warn("journal rebroadcast rejected, nonce gap", "hash", e.Hash, "from", e.From, "nonce", e.Nonce, "err", xerr)
+			sdk.journal.advance(e.Hash, JournalRejected)
+		}
+	}
+	return nil
+}
@@ -1,14 +1,43 @@
 package sdk
 
-// Logger interface
+// Logger is a leveled structured logger, ctx is a list of key/value pairs
 type Logger interface {
-	Info(string, ...interface{})
-	Warn(string, ...interface{})
-	Error(string, ...interface{})
+	Debug(msg string, ctx ...interface{})
+	Info(msg string, ctx ...interface{})
+	Warn(msg string, ctx ...interface{})
+	Error(msg string, ctx ...interface{})
 }
 
-var sdklog Logger
+type nopLogger struct{}
 
-func setLogger(log Logger) {
-	sdklog = log
+// NopLogger returns a Logger which discards all logs, used when NewSDK gets a nil Logger
+func NopLogger() Logger {
+	return nopLogger{}
 }
+
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Warn(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
+
+// ZapSugaredLogger is the subset of *zap.SugaredLogger used by the zap adapter
+type ZapSugaredLogger interface {
+	Debugw(msg string, keysAndValues ...interface{})
+	Infow(msg string, keysAndValues ...interface{})
+	Warnw(msg string, keysAndValues ...interface{})
+	Errorw(msg string, keysAndValues ...interface{})
+}
+
+type zapLogger struct {
+	l ZapSugaredLogger
+}
+
+// NewZapLogger adapts a *zap.SugaredLogger to Logger
+func NewZapLogger(l ZapSugaredLogger) Logger {
+	return &zapLogger{l: l}
+}
+
+func (z *zapLogger) Debug(msg string, ctx ...interface{}) { z.l.Debugw(msg, ctx...) }
+func (z *zapLogger) Info(msg string, ctx ...interface{})  { z.l.Infow(msg, ctx...) }
+func (z *zapLogger) Warn(msg string, ctx ...interface{})  { z.l.Warnw(msg, ctx...) }
+func (z *zapLogger) Error(msg string, ctx ...interface{}) { z.l.Errorw(msg, ctx...) }
@@ -0,0 +1,20 @@
+//go:build go1.21
+// +build go1.21
+
+package sdk
+
+import "log/slog"
+
+type slogLogger struct {
+	l *slog.Logger
+}
+
+// NewSlogLogger adapts a *slog.Logger to Logger
+func NewSlogLogger(l *slog.Logger) Logger {
+	return &slogLogger{l: l}
+}
+
+func (s *slogLogger) Debug(msg string, ctx ...interface{}) { s.l.Debug(msg, ctx...) }
+func (s *slogLogger) Info(msg string, ctx ...interface{})  { s.l.Info(msg, ctx...) }
+func (s *slogLogger) Warn(msg string, ctx ...interface{})  { s.l.Warn(msg, ctx...) }
+func (s *slogLogger) Error(msg string, ctx ...interface{}) { s.l.Error(msg, ctx...) }
@@ -0,0 +1,37 @@
+package sdk
+
+import (
+	"time"
+
+	"github.com/XunleiBlockchain/tc-libs/common"
+)
+
+// RPCMetric describes one BaaS RPC call including its retries
+type RPCMetric struct {
+	Method   string        // RPC方法 如 getBalance
+	Endpoint string        // BaaS接入层地址
+	Duration time.Duration // 含重试的总耗时
+	Attempts int           // 请求次数
+	Status   int           // 最后一次请求的HTTP状态码 无响应为0
+	Code     int           // BaaS返回的错误码 成功为0
+	Err      error         // 请求错误
+}
+
+// SignMetric describes one transaction signing
+type SignMetric struct {
+	Account    common.Address
+	Passphrase bool // 是否使用密码签名 否则使用已解锁账户
+	Duration   time.Duration
+	Err        error
+}
+
+// Metrics is the instrumentation hook invoked for every RPC call and signing
+type Metrics interface {
+	ObserveRPC(m *RPCMetric)
+	ObserveSign(m *SignMetric)
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) ObserveRPC(*RPCMetric)   {}
+func (nopMetrics) ObserveSign(*SignMetric) {}
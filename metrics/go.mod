@@ -0,0 +1,13 @@
+module github.com/XunleiBlockchain/baas-sdk-go/metrics
+
+go 1.13
+
+replace (
+	github.com/XunleiBlockchain/baas-sdk-go => ../
+	github.com/tjfoc/gmsm => github.com/bcscb8/gmsm v0.0.0-20191220070229-b97b35b41ab6
+)
+
+require (
+	github.com/XunleiBlockchain/baas-sdk-go v0.0.0-00010101000000-000000000000
+	github.com/prometheus/client_golang v1.11.1
+)
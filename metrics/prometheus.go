@@ -0,0 +1,89 @@
+// Package metrics provides a Prometheus collector for the SDK metrics hook
+package metrics
+
+import (
+	"strconv"
+
+	sdk "github.com/XunleiBlockchain/baas-sdk-go"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Prometheus implements sdk.Metrics with Prometheus counters and histograms
+type Prometheus struct {
+	rpcRequests *prometheus.CounterVec
+	rpcDuration *prometheus.HistogramVec
+	rpcRetries  *prometheus.CounterVec
+	signs       *prometheus.CounterVec
+	signLatency *prometheus.HistogramVec
+}
+
+// NewPrometheus creates the collectors under namespace and registers them to reg,
+// prometheus.DefaultRegisterer is used if reg is nil.
+func NewPrometheus(reg prometheus.Registerer, namespace string) (*Prometheus, error) {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+	p := &Prometheus{
+		rpcRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "rpc",
+			Name:      "requests_total",
+			Help:      "Number of BaaS RPC calls by method, HTTP status and BaaS code.",
+		}, []string{"method", "status", "code"}),
+		rpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "rpc",
+			Name:      "duration_seconds",
+			Help:      "Duration of BaaS RPC calls including retries.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"}),
+		rpcRetries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "rpc",
+			Name:      "retries_total",
+			Help:      "Number of retried BaaS RPC requests.",
+		}, []string{"method"}),
+		signs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "sign",
+			Name:      "total",
+			Help:      "Number of transaction signings by mode and result.",
+		}, []string{"mode", "result"}),
+		signLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "sign",
+			Name:      "duration_seconds",
+			Help:      "Duration of transaction signings, passphrase signing includes key decryption.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"mode"}),
+	}
+	for _, c := range []prometheus.Collector{p.rpcRequests, p.rpcDuration, p.rpcRetries, p.signs, p.signLatency} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// ObserveRPC implements sdk.Metrics
+func (p *Prometheus) ObserveRPC(m *sdk.RPCMetric) {
+	p.rpcRequests.WithLabelValues(m.Method, strconv.Itoa(m.Status), strconv.Itoa(m.Code)).Inc()
+	p.rpcDuration.WithLabelValues(m.Method).Observe(m.Duration.Seconds())
+	if m.Attempts > 1 {
+		p.rpcRetries.WithLabelValues(m.Method).Add(float64(m.Attempts - 1))
+	}
+}
+
+// ObserveSign implements sdk.Metrics
+func (p *Prometheus) ObserveSign(m *sdk.SignMetric) {
+	mode := "unlocked"
+	if m.Passphrase {
+		mode = "passphrase"
+	}
+	result := "ok"
+	if m.Err != nil {
+		result = "error"
+	}
+	p.signs.WithLabelValues(mode, result).Inc()
+	p.signLatency.WithLabelValues(mode).Observe(m.Duration.Seconds())
+}
@@ -0,0 +1,68 @@
+package sdk
+
+import (
+	"context"
+	"net/http"
+)
+
+// Request is one HTTP attempt of a BaaS JSON-RPC call passed through the middleware chain
+type Request struct {
+	Context context.Context
+	Method  string      // JSON-RPC方法 如 tcapi_getBalance
+	API     string      // BaaS接入层接口 如 getBalance
+	URL     string      // 含from等查询参数
+	Header  http.Header // 附加的HTTP请求头
+	Body    []byte      // JSON-RPC请求体 含auth字段
+}
+
+// Response is the BaaS reply of a Request
+type Response struct {
+	StatusCode int // HTTP状态码 无响应为0
+	Body       []byte
+}
+
+// RoundTripper sends a Request to BaaS
+type RoundTripper interface {
+	RoundTrip(req *Request) (*Response, error)
+}
+
+// RoundTripperFunc adapts a function to RoundTripper
+type RoundTripperFunc func(req *Request) (*Response, error)
+
+// RoundTrip implements RoundTripper
+func (f RoundTripperFunc) RoundTrip(req *Request) (*Response, error) {
+	return f(req)
+}
+
+// Middleware wraps the next RoundTripper, e.g. to add headers, audit, cache or inject faults.
+// It runs once per attempt, so retries go through the whole chain again.
+type Middleware func(next RoundTripper) RoundTripper
+
+// chain wraps rt with mws, mws[0] being the outermost
+func chain(rt RoundTripper, mws ...Middleware) RoundTripper {
+	for i := len(mws) - 1; i >= 0; i-- {
+		rt = mws[i](rt)
+	}
+	return rt
+}
+
+// SetHeader returns a Middleware which sets the HTTP header key to value on every request
+func SetHeader(key, value string) Middleware {
+	return func(next RoundTripper) RoundTripper {
+		return RoundTripperFunc(func(req *Request) (*Response, error) {
+			req.Header.Set(key, value)
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// httpTransport is the innermost RoundTripper posting requests over the long connection client
+type httpTransport struct {
+	log  Logger
+	host string
+}
+
+func (t *httpTransport) RoundTrip(req *Request) (*Response, error) {
+	body, status, err := httpPostWithLongConn(req.Context, t.log, req.URL, t.host, req.Header, req.Body)
+	return &Response{StatusCode: status, Body: body}, err
+}
@@ -0,0 +1,302 @@
+package sdk
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/XunleiBlockchain/tc-libs/common"
+)
+
+// paramError is a field-level error of the request params, joined to ErrParams
+type paramError struct {
+	field string // 如 params[0].from
+	msg   string
+}
+
+func (e *paramError) Error() string {
+	return e.field + ": " + e.msg
+}
+
+func newParamError(field, format string, a ...interface{}) error {
+	return &paramError{field: field, msg: fmt.Sprintf(format, a...)}
+}
+
+// paramList decodes the positional params of a SDK method. Every getter validates
+// its element and keeps the first error; an absent optional element decodes to
+// the zero value, so callers read them after checking len.
+type paramList struct {
+	args []interface{}
+	err  error
+}
+
+// parseParams expects params to be a list of min to max elements
+func parseParams(params interface{}, min, max int) *paramList {
+	p := &paramList{}
+	if params == nil {
+		params = []interface{}{}
+	}
+	args, ok := params.([]interface{})
+	if !ok {
+		p.err = newParamError("params", "expected array, got %s", jsonType(params))
+		return p
+	}
+	if len(args) < min || len(args) > max {
+		if min == max {
+			p.err = newParamError("params", "expected %d elements, got %d", min, len(args))
+		} else {
+			p.err = newParamError("params", "expected %d to %d elements, got %d", min, max, len(args))
+		}
+		return p
+	}
+	p.args = args
+	return p
+}
+
+func (p *paramList) len() int {
+	return len(p.args)
+}
+
+// error returns ErrParams with the first field error, nil if all getters succeeded
+func (p *paramList) error() *Error {
+	if p.err == nil {
+		return nil
+	}
+	return ErrParams.Join(p.err)
+}
+
+// value returns element i, nil if it is absent or an error occurred
+func (p *paramList) value(i int) (interface{}, string, bool) {
+	if p.err != nil || i >= len(p.args) {
+		return nil, "", false
+	}
+	return p.args[i], fmt.Sprintf("params[%d]", i), true
+}
+
+func (p *paramList) string(i int) string {
+	x, field, ok := p.value(i)
+	if !ok {
+		return ""
+	}
+	s, err := toString(x, field)
+	p.err = err
+	return s
+}
+
+func (p *paramList) bool(i int) bool {
+	x, field, ok := p.value(i)
+	if !ok {
+		return false
+	}
+	b, err := toBool(x, field)
+	p.err = err
+	return b
+}
+
+func (p *paramList) address(i int) common.Address {
+	x, field, ok := p.value(i)
+	if !ok {
+		return common.Address{}
+	}
+	addr, err := toAddress(x, field)
+	p.err = err
+	return addr
+}
+
+func (p *paramList) hash(i int) string {
+	x, field, ok := p.value(i)
+	if !ok {
+		return ""
+	}
+	h, err := toHash(x, field)
+	p.err = err
+	return h
+}
+
+func (p *paramList) hex(i int) []byte {
+	x, field, ok := p.value(i)
+	if !ok {
+		return nil
+	}
+	b, err := toHexBytes(x, field)
+	p.err = err
+	return b
+}
+
+func (p *paramList) uint(i int, bitSize int) uint64 {
+	x, field, ok := p.value(i)
+	if !ok {
+		return 0
+	}
+	n, err := toUint(x, field, bitSize)
+	p.err = err
+	return n
+}
+
+// argsParser is implemented by the object params SendTxArgs, CallArgs and ContractExtension
+type argsParser interface {
+	parseFromArgs(x interface{}, field string) error
+}
+
+// decode parses element i into v
+func (p *paramList) decode(i int, v argsParser) {
+	x, field, ok := p.value(i)
+	if !ok {
+		return
+	}
+	p.err = v.parseFromArgs(x, field)
+}
+
+// raw returns element i undecoded
+func (p *paramList) raw(i int) interface{} {
+	x, _, _ := p.value(i)
+	return x
+}
+
+// ------------------------------- converters -------------------------------
+func jsonType(x interface{}) string {
+	switch x.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case float64, json.Number, int, int64, uint, uint64:
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", x)
+	}
+}
+
+func toString(x interface{}, field string) (string, error) {
+	s, ok := x.(string)
+	if !ok {
+		return "", newParamError(field, "expected string, got %s", jsonType(x))
+	}
+	return s, nil
+}
+
+func toBool(x interface{}, field string) (bool, error) {
+	b, ok := x.(bool)
+	if !ok {
+		return false, newParamError(field, "expected bool, got %s", jsonType(x))
+	}
+	return b, nil
+}
+
+func toObject(x interface{}, field string) (map[string]interface{}, error) {
+	obj, ok := x.(map[string]interface{})
+	if !ok {
+		return nil, newParamError(field, "expected object, got %s", jsonType(x))
+	}
+	return obj, nil
+}
+
+func toAddress(x interface{}, field string) (common.Address, error) {
+	s, err := toString(x, field)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if !common.IsHexAddress(s) {
+		return common.Address{}, newParamError(field, "invalid address %q", s)
+	}
+	return common.HexToAddress(s), nil
+}
+
+// toHash checks x is a 32 bytes hex string and returns it as is
+func toHash(x interface{}, field string) (string, error) {
+	s, err := toString(x, field)
+	if err != nil {
+		return "", err
+	}
+	if b, err := hex.DecodeString(trimHexPrefix(s)); err != nil || len(b) != common.HashLength {
+		return "", newParamError(field, "invalid hash %q", s)
+	}
+	return s, nil
+}
+
+// toHexBytes decodes a hex string with optional 0x prefix, an odd length is left padded
+func toHexBytes(x interface{}, field string) ([]byte, error) {
+	s, err := toString(x, field)
+	if err != nil {
+		return nil, err
+	}
+	h := trimHexPrefix(s)
+	if len(h)%2 == 1 {
+		h = "0" + h
+	}
+	b, err := hex.DecodeString(h)
+	if err != nil {
+		return nil, newParamError(field, "invalid hex %q", s)
+	}
+	return b, nil
+}
+
+// toBig accepts a non-negative integer as a JSON number, a 0x prefixed hex string
+// or a decimal string
+func toBig(x interface{}, field string) (*big.Int, error) {
+	var (
+		n  *big.Int
+		ok bool
+	)
+	switch v := x.(type) {
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			n, _ = new(big.Float).SetFloat64(v).Int(nil)
+			ok = true
+		}
+	case json.Number:
+		n, ok = new(big.Int).SetString(v.String(), 10)
+	case string:
+		if strings.HasPrefix(v, "0x") || strings.HasPrefix(v, "0X") {
+			n, ok = new(big.Int).SetString(v[2:], 16)
+		} else {
+			n, ok = new(big.Int).SetString(v, 10)
+		}
+	case int:
+		n, ok = big.NewInt(int64(v)), true
+	case int64:
+		n, ok = big.NewInt(v), true
+	case uint:
+		n, ok = new(big.Int).SetUint64(uint64(v)), true
+	case uint64:
+		n, ok = new(big.Int).SetUint64(v), true
+	case *big.Int:
+		n, ok = v, v != nil
+	default:
+		return nil, newParamError(field, "expected number or numeric string, got %s", jsonType(x))
+	}
+	if !ok {
+		return nil, newParamError(field, "invalid number %v", x)
+	}
+	if n.Sign() < 0 {
+		return nil, newParamError(field, "negative number %v", x)
+	}
+	return n, nil
+}
+
+func toUint(x interface{}, field string, bitSize int) (uint64, error) {
+	n, err := toBig(x, field)
+	if err != nil {
+		return 0, err
+	}
+	if n.BitLen() > bitSize {
+		return 0, newParamError(field, "%v overflows uint%d", x, bitSize)
+	}
+	return n.Uint64(), nil
+}
+
+func trimHexPrefix(s string) string {
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		return s[2:]
+	}
+	return s
+}
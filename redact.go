@@ -0,0 +1,129 @@
+package sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Payload log levels, see Config.PayloadLog
+const (
+	PayloadLogRedacted = "redacted" // 记录请求/响应内容 敏感字段打码(默认)
+	PayloadLogNone     = "none"     // 不记录请求/响应内容 仅记录长度
+	PayloadLogFull     = "full"     // 完整记录请求/响应内容 仅用于调试
+)
+
+const redactedMask = "******"
+
+// sensitiveKeys are log keys and JSON fields whose values are masked
+var sensitiveKeys = map[string]bool{
+	"passwd":     true,
+	"password":   true,
+	"passphrase": true,
+	"mnemonic":   true,
+	"key":        true,
+	"privatekey": true,
+	"origin":     true,
+	"sign":       true,
+	"signature":  true,
+}
+
+// Payload marks a request or response body in log key/value pairs, so that
+// the redact logger logs it according to the payload log level.
+type Payload []byte
+
+type redactLogger struct {
+	log   Logger
+	level string
+}
+
+// NewRedactLogger wraps log so that sensitive values are masked and Payload
+// values are logged according to level.
+func NewRedactLogger(log Logger, level string) (Logger, error) {
+	switch level {
+	case "":
+		level = PayloadLogRedacted
+	case PayloadLogRedacted, PayloadLogNone, PayloadLogFull:
+	default:
+		return nil, fmt.Errorf("invalid payload log level: %s", level)
+	}
+	return &redactLogger{log: log, level: level}, nil
+}
+
+func (l *redactLogger) Debug(msg string, ctx ...interface{}) {
+	l.log.Debug(msg, l.redact(ctx)...)
+}
+
+func (l *redactLogger) Info(msg string, ctx ...interface{}) {
+	l.log.Info(msg, l.redact(ctx)...)
+}
+
+func (l *redactLogger) Warn(msg string, ctx ...interface{}) {
+	l.log.Warn(msg, l.redact(ctx)...)
+}
+
+func (l *redactLogger) Error(msg string, ctx ...interface{}) {
+	l.log.Error(msg, l.redact(ctx)...)
+}
+
+func (l *redactLogger) redact(ctx []interface{}) []interface{} {
+	res := make([]interface{}, len(ctx))
+	for i, v := range ctx {
+		if i%2 == 1 {
+			if k, ok := ctx[i-1].(string); ok && sensitiveKeys[strings.ToLower(k)] {
+				v = redactedMask
+			}
+		}
+		if p, ok := v.(Payload); ok {
+			v = l.payload(p)
+		}
+		res[i] = v
+	}
+	return res
+}
+
+func (l *redactLogger) payload(p Payload) string {
+	switch l.level {
+	case PayloadLogFull:
+		return string(p)
+	case PayloadLogNone:
+		return fmt.Sprintf("[%d bytes]", len(p))
+	}
+	var v interface{}
+	if err := json.Unmarshal(p, &v); err != nil {
+		// not json, e.g. a raw transaction
+		return fmt.Sprintf("%s[%d bytes]", redactedMask, len(p))
+	}
+	data, _ := json.Marshal(redactJSON(v))
+	return string(data)
+}
+
+// redactJSON masks sensitive fields and raw transaction params in a decoded JSON value
+func redactJSON(v interface{}) interface{} {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(x))
+		for k, e := range x {
+			if sensitiveKeys[strings.ToLower(k)] {
+				res[k] = redactedMask
+				continue
+			}
+			res[k] = redactJSON(e)
+		}
+		// params of sendRawTransaction is the signed raw transaction
+		if method, ok := x["method"].(string); ok && strings.HasSuffix(method, "sendRawTransaction") {
+			if _, ok := x["params"]; ok {
+				res["params"] = redactedMask
+			}
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(x))
+		for i, e := range x {
+			res[i] = redactJSON(e)
+		}
+		return res
+	default:
+		return v
+	}
+}
@@ -1,11 +1,13 @@
 package sdk
 
 import (
+	"context"
 	"fmt"
 	"math/big"
-	"runtime/debug"
+	"sync"
 	"time"
 
+	"github.com/XunleiBlockchain/baas-sdk-go/types"
 	"github.com/XunleiBlockchain/tc-libs/accounts"
 	"github.com/XunleiBlockchain/tc-libs/accounts/keystore"
 	"github.com/XunleiBlockchain/tc-libs/common"
@@ -16,73 +18,305 @@ type SDKImpl struct {
 	cfg       *Config
 	am        *accounts.Manager
 	signParam *big.Int
-	gasPrice  *big.Int
+	signHash  string // 交易签名哈希算法 为空即keccak256
+	gasPrice  *gasPriceCache
 	nonceLock *addrLocker
+	log       Logger
+	metrics   Metrics
+	tracer    Tracer
+	ctx       context.Context
+	hd        *hdWallet
 	c         *client
+
+	unlocks    *unlockTracker
+	unlockErrs map[string]string
+	watcher    *watcher
+	journal    *txJournal // 未配置Journal时为nil
+
+	idempotency    IdempotencyStore
+	idempotencyTTL time.Duration
+
+	quit      chan struct{}
+	closeOnce *sync.Once
+}
+
+// gasPriceCache holds the gas price fetched from BaaS, shared by the WithContext copies
+type gasPriceCache struct {
+	mu    sync.RWMutex
+	price *big.Int
+	err   error // 最近一次获取失败的原因
+}
+
+func (g *gasPriceCache) get() *big.Int {
+	price, _ := g.status()
+	return price
+}
+
+// status returns the gas price, nil if never fetched, and why the last fetch failed
+func (g *gasPriceCache) status() (*big.Int, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.price, g.err
+}
+
+func (g *gasPriceCache) set(price *big.Int, err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if price != nil {
+		g.price = price
+	}
+	g.err = err
 }
 
 // NewSDK return a pointer to SDKImpl
 func NewSDK(cfg *Config, log Logger) (*SDKImpl, error) {
-	sdklog = log
+	if log == nil {
+		log = NopLogger()
+	}
+	log, err := NewRedactLogger(log, cfg.PayloadLog)
+	if err != nil {
+		return nil, fmt.Errorf("New: NewRedactLogger error: %v", err)
+	}
+	if err := types.CheckSignHashType(cfg.SignHash); err != nil {
+		return nil, fmt.Errorf("New: %v", err)
+	}
+	// 0. crypto, shared by the SDKs of the process
+	if err := acquireCrypto(cfg.CryptoType); err != nil {
+		return nil, fmt.Errorf("New: acquireCrypto error: %v", err)
+	}
+	// undo what has been set up if NewSDK fails, as Close would
+	var (
+		started bool
+		sdk     *SDKImpl
+		ks      *keystore.KeyStore
+		unlocks = &unlockTracker{}
+	)
+	defer func() {
+		if started {
+			return
+		}
+		if sdk != nil {
+			sdk.Close()
+			return
+		}
+		releaseCrypto()
+		if ks != nil {
+			unlocks.lockAll(ks)
+		}
+		closeConfig(cfg)
+	}()
 	// 1. account manager
 	am, err := makeAccountManager(cfg.Keystore)
 	if err != nil {
 		return nil, fmt.Errorf("New: makeAccountManager error: %v", err)
 	}
 	// 2. keystore
-	ks := am.Backends(keystore.KeyStoreType)[0].(*keystore.KeyStore)
+	ks = am.Backends(keystore.KeyStoreType)[0].(*keystore.KeyStore)
+	unlockErrs := make(map[string]string)
 	for addr, passwd := range cfg.UnlockAccounts {
 		acc := accounts.Account{Address: common.HexToAddress(addr)}
 		_, err = am.Find(acc)
-		if err != nil {
-			continue
+		if err == nil {
+			err = unlocks.unlock(ks, acc, passwd, 0)
 		}
-		err = ks.Unlock(acc, passwd)
-		debug.FreeOSMemory()
 		if err != nil {
-			continue
+			log.Error("New: unlock account fail", "account", addr, "err", err)
+			unlockErrs[addr] = err.Error()
+		}
+	}
+	// 3. hd wallet
+	hd, err := newHDWallet(cfg.HDPath)
+	if err != nil {
+		return nil, fmt.Errorf("New: newHDWallet error: %v", err)
+	}
+	if cfg.Mnemonic != "" {
+		if err = hd.setMnemonic(cfg.Mnemonic, cfg.MnemonicPasswd, false); err != nil {
+			return nil, fmt.Errorf("New: setMnemonic error: %v", err)
 		}
 	}
-	// 3. get client
-	cli, err := newClient(cfg)
+	// 4. get client
+	cli, err := newClient(cfg, log)
 	if err != nil {
 		return nil, fmt.Errorf("New: newClient error: %v", err)
 	}
-	// 3. get chain id
-	chainID, err := cli.getChainID()
+	// 5. get chain id
+	chainID, err := cli.getChainID(context.Background())
 	if err != nil {
 		return nil, fmt.Errorf("New: getChainID error: %v", err)
 	}
-	// 4. get SDKImpl
-	sdk := &SDKImpl{
-		cfg:       cfg,
-		signParam: big.NewInt(0).SetBytes([]byte(fmt.Sprintf("%d", chainID))),
-		am:        am,
-		nonceLock: &addrLocker{},
-		c:         cli,
+	// 6. get SDKImpl
+	metrics := cfg.Metrics
+	if metrics == nil {
+		metrics = nopMetrics{}
+	}
+	tracer := cfg.Tracer
+	if tracer == nil {
+		tracer = nopTracer{}
+	}
+	idempotency := cfg.Idempotency
+	if idempotency == nil {
+		idempotency = NewMemoryIdempotencyStore()
+	}
+	idempotencyTTL := cfg.IdempotencyTTL
+	if idempotencyTTL <= 0 {
+		idempotencyTTL = defaultIdempotencyTTL
+	}
+	w := newWatcher(cli, cfg.PollInterval, log)
+	cli.onSent = w.track
+	sdk = &SDKImpl{
+		cfg:        cfg,
+		signParam:  ChainSignParam(chainID),
+		signHash:   cfg.SignHash,
+		am:         am,
+		nonceLock:  &addrLocker{},
+		log:        log,
+		metrics:    metrics,
+		tracer:     tracer,
+		hd:         hd,
+		c:          cli,
+		unlocks:    unlocks,
+		unlockErrs: unlockErrs,
+		watcher:    w,
+		gasPrice:   &gasPriceCache{},
+		quit:       make(chan struct{}),
+		closeOnce:  &sync.Once{},
+
+		idempotency:    idempotency,
+		idempotencyTTL: idempotencyTTL,
+	}
+	// 7. recover the journaled transactions
+	if cfg.Journal != nil {
+		sdk.journal = newTxJournal(cfg.Journal, log)
+		if err := sdk.recoverJournal(context.Background()); err != nil {
+			return nil, fmt.Errorf("New: recoverJournal error: %v", err)
+		}
+		go sdk.trackJournal()
 	}
 	go sdk.getLoop()
+	started = true
 	return sdk, nil
 }
 
+// ChainSignParam returns the transaction sign param of the chain chainID
+func ChainSignParam(chainID int64) *big.Int {
+	return big.NewInt(0).SetBytes([]byte(fmt.Sprintf("%d", chainID)))
+}
+
+// UnlockErrors returns the configured UnlockAccounts that failed to unlock at startup and why
+func (sdk *SDKImpl) UnlockErrors() map[string]string {
+	errs := make(map[string]string, len(sdk.unlockErrs))
+	for addr, err := range sdk.unlockErrs {
+		errs[addr] = err
+	}
+	return errs
+}
+
+// WithContext returns a shallow copy of sdk whose calls run under ctx: the span
+// in ctx becomes the parent of the call spans, and cancelling ctx aborts BaaS requests.
+func (sdk *SDKImpl) WithContext(ctx context.Context) *SDKImpl {
+	if ctx == nil {
+		panic("nil context")
+	}
+	s := *sdk
+	s.ctx = ctx
+	return &s
+}
+
+func (sdk *SDKImpl) context() context.Context {
+	if sdk.ctx != nil {
+		return sdk.ctx
+	}
+	return context.Background()
+}
+
+// startSpan starts the span of an SDK call
+func (sdk *SDKImpl) startSpan(name string) (context.Context, *span) {
+	return startSpan(sdk.tracer, sdk.context(), "sdk."+name)
+}
+
+// Close stops refreshing the gas price, ends the subscriptions, locks the unlocked
+// accounts so their keys leave memory and closes Config.Credentials, Config.Journal
+// and Config.Idempotency if they have a Close method. Calls in flight are not
+// waited for, callers stop sending requests first.
+func (sdk *SDKImpl) Close() {
+	sdk.closeOnce.Do(func() {
+		close(sdk.quit)
+		releaseCrypto()
+		sdk.watcher.close()
+		sdk.unlocks.lockAll(sdk.keyStore())
+		closeConfig(sdk.cfg)
+	})
+}
+
+// closeConfig closes the Credentials, Journal and Idempotency of cfg that have a Close method
+func closeConfig(cfg *Config) {
+	if c, ok := cfg.Credentials.(interface{ Close() }); ok {
+		c.Close()
+	}
+	if c, ok := cfg.Journal.(interface{ Close() }); ok {
+		c.Close()
+	}
+	if c, ok := cfg.Idempotency.(interface{ Close() }); ok {
+		c.Close()
+	}
+}
+
+// Readiness is the result of SDKImpl.Ready, the fields name the failed checks
+type Readiness struct {
+	Ready    bool              `json:"ready"`
+	BaaS     string            `json:"baas,omitempty"`     // BaaS不可达的原因
+	Accounts map[string]string `json:"accounts,omitempty"` // 未解锁的UnlockAccounts及原因
+	GasPrice string            `json:"gasPrice,omitempty"` // GetGasPrice时GasPrice未知的原因
+}
+
+// Ready checks whether the SDK can send transactions: BaaS answers blockNumber
+// within ctx, the Config.UnlockAccounts are unlocked and, with Config.GetGasPrice,
+// the gas price has been fetched.
+func (sdk *SDKImpl) Ready(ctx context.Context) *Readiness {
+	r := &Readiness{}
+	if _, xerr := sdk.c.getBlockNumber(ctx); xerr.Code != 0 {
+		r.BaaS = xerr.Error()
+	}
+	ks := sdk.keyStore()
+	for addr := range sdk.cfg.UnlockAccounts {
+		reason, failed := sdk.unlockErrs[addr]
+		if !failed && !sdk.unlocks.state(ks, common.HexToAddress(addr)).Unlocked {
+			reason, failed = "locked", true
+		}
+		if failed {
+			if r.Accounts == nil {
+				r.Accounts = make(map[string]string)
+			}
+			r.Accounts[addr] = reason
+		}
+	}
+	if price, err := sdk.gasPrice.status(); sdk.cfg.GetGasPrice && price == nil {
+		r.GasPrice = "not fetched"
+		if err != nil {
+			r.GasPrice = err.Error()
+		}
+	}
+	r.Ready = r.BaaS == "" && len(r.Accounts) == 0 && r.GasPrice == ""
+	return r
+}
+
+// getLoop refreshes the gas price at once and then every 30s until Close
 func (sdk *SDKImpl) getLoop() {
 	interval := 30 * time.Second
-	timer := time.NewTimer(interval)
+	timer := time.NewTimer(0)
 	defer timer.Stop()
 	for {
-		// timer may be not active, and fired
-		if !timer.Stop() {
-			select {
-			case <-timer.C:
-			default:
-			}
-		}
-		timer.Reset(interval)
 		select {
 		case <-timer.C:
-			if gasPrice, err := sdk.c.getGasPrice(); err == nil {
-				sdk.gasPrice = gasPrice
+			gasPrice, xerr := sdk.c.getGasPrice(context.Background())
+			if xerr != nil {
+				sdk.gasPrice.set(nil, xerr)
+			} else {
+				sdk.gasPrice.set(gasPrice, nil)
 			}
+			timer.Reset(interval)
+		case <-sdk.quit:
+			return
 		}
 	}
 }
@@ -1,42 +1,48 @@
 package sdk
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"runtime/debug"
-	"strconv"
+	"time"
 
 	"github.com/XunleiBlockchain/tc-libs/accounts"
 	"github.com/XunleiBlockchain/tc-libs/accounts/keystore"
 	"github.com/XunleiBlockchain/tc-libs/bal"
 	"github.com/XunleiBlockchain/tc-libs/common"
+	"github.com/XunleiBlockchain/tc-libs/crypto"
 )
 
-func (sdk *SDKImpl) NewAccount(params interface{}) (interface{}, *Error) {
-	defer catchInterfacePanic()
-	args := params.([]interface{})
-	if len(args) != 1 {
-		return "", ErrParams
+func (sdk *SDKImpl) NewAccount(params interface{}) (_ interface{}, xerr *Error) {
+	_, span := sdk.startSpan("NewAccount")
+	defer span.finish(&xerr)
+	defer sdk.catchInterfacePanic(&xerr)
+	p := parseParams(params, 1, 1)
+	passwd := p.string(0)
+	if xerr := p.error(); xerr != nil {
+		return "", xerr
 	}
-	passwd := args[0].(string)
 	ks := sdk.am.Backends(keystore.KeyStoreType)[0].(*keystore.KeyStore)
 	acc, err := ks.NewAccount(passwd)
 	if err == nil {
-		err = ks.Unlock(acc, passwd)
-		debug.FreeOSMemory()
+		err = sdk.unlocks.unlock(ks, acc, passwd, sdk.cfg.UnlockTimeout)
 		if err != nil {
-			sdklog.Error("new account unlock fail", "account", acc, "passwd", passwd)
+			sdk.log.Error("new account unlock fail", "account", acc.Address, "err", err)
 		}
 		return acc.Address, nil
 	}
-	sdklog.Error("new account fail", "account", acc, "passwd", passwd)
+	sdk.log.Error("new account fail", "err", err)
 	return common.Address{}, ErrNewAccount.Join(err)
 }
 
-func (sdk *SDKImpl) Accounts(params interface{}) (interface{}, *Error) {
-	defer catchInterfacePanic()
-	args := params.([]interface{})
-	if len(args) != 0 {
-		return "", ErrParams
+func (sdk *SDKImpl) Accounts(params interface{}) (_ interface{}, xerr *Error) {
+	_, span := sdk.startSpan("Accounts")
+	defer span.finish(&xerr)
+	defer sdk.catchInterfacePanic(&xerr)
+	p := parseParams(params, 0, 0)
+	if xerr := p.error(); xerr != nil {
+		return "", xerr
 	}
 	addresses := make([]common.Address, 0)
 	for _, wallet := range sdk.am.Wallets() {
@@ -47,130 +53,127 @@ func (sdk *SDKImpl) Accounts(params interface{}) (interface{}, *Error) {
 	return addresses, nil
 }
 
-func (sdk *SDKImpl) GetBalance(params interface{}) (interface{}, *Error) {
-	defer catchInterfacePanic()
-	args := params.([]interface{})
-	if len(args) != 1 {
-		return "", ErrParams
-	}
-	addr := args[0].(string)
-	account := accounts.Account{Address: common.HexToAddress(addr)}
+func (sdk *SDKImpl) GetBalance(params interface{}) (_ interface{}, xerr *Error) {
+	ctx, span := sdk.startSpan("GetBalance")
+	defer span.finish(&xerr)
+	defer sdk.catchInterfacePanic(&xerr)
+	p := parseParams(params, 1, 1)
+	addr := p.string(0)
+	account := accounts.Account{Address: p.address(0)}
+	if xerr := p.error(); xerr != nil {
+		return "", xerr
+	}
 	_, err := sdk.am.Find(account)
 	if err != nil {
 		return 0, ErrAccountFind.Join(err)
 	}
-	return sdk.c.getBalance(addr)
+	return sdk.c.getBalance(ctx, addr)
 }
 
-func (sdk *SDKImpl) BlockNumber() (interface{}, *Error) {
-	return sdk.c.getBlockNumber()
+func (sdk *SDKImpl) BlockNumber() (_ interface{}, xerr *Error) {
+	ctx, span := sdk.startSpan("BlockNumber")
+	defer span.finish(&xerr)
+	return sdk.c.getBlockNumber(ctx)
 }
 
-func (sdk *SDKImpl) GetTransactionCount(params interface{}) (interface{}, *Error) {
-	defer catchInterfacePanic()
-	args := params.([]interface{})
-	if len(args) != 1 {
-		return "", ErrParams
-	}
-	addr := args[0].(string)
-	account := accounts.Account{Address: common.HexToAddress(addr)}
+func (sdk *SDKImpl) GetTransactionCount(params interface{}) (_ interface{}, xerr *Error) {
+	ctx, span := sdk.startSpan("GetTransactionCount")
+	defer span.finish(&xerr)
+	defer sdk.catchInterfacePanic(&xerr)
+	p := parseParams(params, 1, 1)
+	addr := p.string(0)
+	account := accounts.Account{Address: p.address(0)}
+	if xerr := p.error(); xerr != nil {
+		return "", xerr
+	}
 	_, err := sdk.am.Find(account)
 	if err != nil {
 		return 0, ErrAccountFind.Join(err)
 	}
-	return sdk.c.getNonce(addr)
+	return sdk.c.getNonce(ctx, addr)
 }
 
-func (sdk *SDKImpl) GetTransactionByHash(params interface{}) (interface{}, *Error) {
-	defer catchInterfacePanic()
-	args := params.([]interface{})
-	if len(args) != 2 {
-		return "", ErrParams
-	}
-	from := args[0].(string)
-	account := accounts.Account{Address: common.HexToAddress(from)}
+func (sdk *SDKImpl) GetTransactionByHash(params interface{}) (_ interface{}, xerr *Error) {
+	ctx, span := sdk.startSpan("GetTransactionByHash")
+	defer span.finish(&xerr)
+	defer sdk.catchInterfacePanic(&xerr)
+	p := parseParams(params, 2, 2)
+	from := p.string(0)
+	account := accounts.Account{Address: p.address(0)}
+	hash := p.hash(1)
+	if xerr := p.error(); xerr != nil {
+		return "", xerr
+	}
 	_, err := sdk.am.Find(account)
 	if err != nil {
 		return 0, ErrAccountFind.Join(err)
 	}
-	hash := args[1].(string)
-	return sdk.c.getTransactionByHash(from, hash)
+	return sdk.c.getTransactionByHash(ctx, from, hash)
 }
 
-func (sdk *SDKImpl) GetTransactionReceipt(params interface{}) (interface{}, *Error) {
-	defer catchInterfacePanic()
-	args := params.([]interface{})
-	if len(args) != 1 {
-		return "", ErrParams
-	}
-	hash := args[0].(string)
-	return sdk.c.getTransactionReceipt(hash)
+func (sdk *SDKImpl) GetTransactionReceipt(params interface{}) (_ interface{}, xerr *Error) {
+	ctx, span := sdk.startSpan("GetTransactionReceipt")
+	defer span.finish(&xerr)
+	defer sdk.catchInterfacePanic(&xerr)
+	p := parseParams(params, 1, 1)
+	hash := p.hash(0)
+	if xerr := p.error(); xerr != nil {
+		return "", xerr
+	}
+	return sdk.c.getTransactionReceipt(ctx, hash)
 }
 
-func (sdk *SDKImpl) GetBlockByNumber(params interface{}) (interface{}, *Error) {
-	defer catchInterfacePanic()
-	var (
-		number       uint64
-		fullTxReturn bool
-		err          error
-	)
-	args := params.([]interface{})
-	if len(args) == 1 {
-		fullTxReturn = false
-	} else if len(args) == 2 {
-		fullTxReturn = args[1].(bool)
-	} else {
-		return "", ErrParams
-	}
-	switch args[0].(type) {
-	case string:
-		number, err = strconv.ParseUint(args[0].(string), 10, 64)
-		if err != nil {
-			return "", ErrParams
-		}
-	case int:
-		number = uint64(args[0].(int))
-	case uint:
-		number = args[0].(uint64)
-	default:
-		return "", ErrParams
+func (sdk *SDKImpl) GetBlockByNumber(params interface{}) (_ interface{}, xerr *Error) {
+	ctx, span := sdk.startSpan("GetBlockByNumber")
+	defer span.finish(&xerr)
+	defer sdk.catchInterfacePanic(&xerr)
+	p := parseParams(params, 1, 2)
+	number := p.uint(0, 64)
+	fullTxReturn := p.bool(1)
+	if xerr := p.error(); xerr != nil {
+		return "", xerr
 	}
 	s := fmt.Sprintf("0x%x", number)
-	return sdk.c.getBlockByNumber(s, fullTxReturn)
+	return sdk.c.getBlockByNumber(ctx, s, fullTxReturn)
 }
 
-func (sdk *SDKImpl) GetBlockByHash(params interface{}) (interface{}, *Error) {
-	defer catchInterfacePanic()
-	var (
-		hash         string
-		fullTxReturn bool
-	)
-	args := params.([]interface{})
-	if len(args) == 1 {
-		hash = args[0].(string)
-		fullTxReturn = false
-	} else if len(args) == 2 {
-		hash = args[0].(string)
-		fullTxReturn = args[1].(bool)
-	} else {
-		return "", ErrParams
-	}
-	return sdk.c.getBlockByHash(hash, fullTxReturn)
+func (sdk *SDKImpl) GetBlockByHash(params interface{}) (_ interface{}, xerr *Error) {
+	ctx, span := sdk.startSpan("GetBlockByHash")
+	defer span.finish(&xerr)
+	defer sdk.catchInterfacePanic(&xerr)
+	p := parseParams(params, 1, 2)
+	hash := p.hash(0)
+	fullTxReturn := p.bool(1)
+	if xerr := p.error(); xerr != nil {
+		return "", xerr
+	}
+	return sdk.c.getBlockByHash(ctx, hash, fullTxReturn)
 }
 
-func (sdk *SDKImpl) SendTransaction(params interface{}) (interface{}, *Error) {
-	defer catchInterfacePanic()
-	args := params.([]interface{})
-	if len(args) != 1 && len(args) != 2 {
-		return common.Hash{}, ErrParams
-	}
+func (sdk *SDKImpl) SendTransaction(params interface{}) (_ interface{}, xerr *Error) {
+	ctx, span := sdk.startSpan("SendTransaction")
+	defer span.finish(&xerr)
+	defer sdk.catchInterfacePanic(&xerr)
+	p := parseParams(params, 1, 2)
 	var sendTxArgs SendTxArgs
-	err := sendTxArgs.parseFromArgs(args[0])
-	if err != nil {
-		return common.Hash{}, ErrSendTxArgs.Join(err)
+	p.decode(0, &sendTxArgs)
+	passwd := p.string(1)
+	if xerr := p.error(); xerr != nil {
+		return common.Hash{}, xerr
+	}
+	claim, dup, xerr := sdk.claimIdempotency(ctx, &sendTxArgs, nil)
+	if xerr != nil {
+		return common.Hash{}, xerr
+	}
+	if dup != nil {
+		return dup, ErrSuccess
+	}
+	if claim != nil {
+		defer sdk.finishIdempotency(claim)
+		ctx = context.WithValue(ctx, idempotencyClaimKey{}, claim)
 	}
 	if sdk.cfg.GetGasPrice {
-		sendTxArgs.GasPrice = sdk.gasPrice
+		sendTxArgs.GasPrice = sdk.gasPrice.get()
 	}
 	account := accounts.Account{Address: sendTxArgs.From}
 	wallet, err := sdk.am.Find(account)
@@ -181,66 +184,84 @@ func (sdk *SDKImpl) SendTransaction(params interface{}) (interface{}, *Error) {
 		sdk.nonceLock.lockAddr(sendTxArgs.From)
 		defer sdk.nonceLock.unlockAddr(sendTxArgs.From)
 	}
-	if err = sendTxArgs.setDefaults(sdk.c); err != nil {
+	if err = sendTxArgs.setDefaults(ctx, sdk.c); err != nil {
 		return common.Hash{}, ErrSendTxArgs.Join(err)
 	}
-	tx := sendTxArgs.toTransaction()
+	tx := sendTxArgs.toTransaction(sdk.signHash)
 	stx, ok := tx.(accounts.SingerTx)
 	if !ok {
 		return nil, ErrSendTxArgs.Join(fmt.Errorf("tx is not a SignerTx type"))
 	}
 	// send transaction without password
-	if len(args) == 1 {
-		signed, err := wallet.SignTx(account, stx, sdk.signParam)
-		debug.FreeOSMemory()
+	if p.len() == 1 {
+		signed, err := sdk.signTx(ctx, wallet, account, stx)
 		if err != nil {
 			return common.Hash{}, ErrSDKSignTx.Join(err)
 		}
 		txbal, err := bal.EncodeToBytes(signed)
 		if err != nil {
-			sdklog.Error("SendTransaction bal.EncodeToBytes()", "err", err)
+			sdk.log.Error("SendTransaction bal.EncodeToBytes()", "err", err)
 			return common.Hash{}, ErrBalEncodeToBytes.Join(err)
 		}
-		res, xerr := sdk.c.sendTransaction(common.ToHex(txbal))
+		res, xerr := sdk.broadcast(ctx, signed, &sendTxArgs, common.ToHex(txbal), nil)
 		if xerr.Code != 0 {
 			res = common.Hash{}
 		}
-		sdklog.Info("SendTransaction", "res", "xerr", *xerr)
+		sdk.log.Info("SendTransaction", "res", res, "xerr", *xerr)
 		return res, xerr
 	}
 	// send transaction with password
-	passwd := args[1].(string)
-	signed, err := wallet.SignTxWithPassphrase(account, passwd, stx, sdk.signParam)
-	debug.FreeOSMemory()
+	signed, err := sdk.signTxWithPassphrase(ctx, wallet, account, passwd, stx)
 	if err != nil {
 		return common.Hash{}, ErrSDKSignTxWithPassphrase.Join(err)
 	}
 	txbal, err := bal.EncodeToBytes(signed)
 	if err != nil {
-		sdklog.Error("SendTransaction bal.EncodeToBytes()", "err", err)
+		sdk.log.Error("SendTransaction bal.EncodeToBytes()", "err", err)
 		return common.Hash{}, ErrBalEncodeToBytes.Join(err)
 	}
-	res, xerr := sdk.c.sendTransaction(common.ToHex(txbal))
+	res, xerr := sdk.broadcast(ctx, signed, &sendTxArgs, common.ToHex(txbal), nil)
 	if xerr.Code != 0 {
 		res = common.Hash{}
 	}
-	sdklog.Info("SendTransaction", "res", "xerr", *xerr)
+	sdk.log.Info("SendTransaction", "res", res, "xerr", *xerr)
 	return res, xerr
 }
 
-func (sdk *SDKImpl) SendContractTransaction(params interface{}) (interface{}, *Error) {
-	defer catchInterfacePanic()
-	args := params.([]interface{})
-	if len(args) == 0 || len(args) > 3 {
-		return common.Hash{}, ErrParams
+func (sdk *SDKImpl) SendContractTransaction(params interface{}) (_ interface{}, xerr *Error) {
+	ctx, span := sdk.startSpan("SendContractTransaction")
+	defer span.finish(&xerr)
+	defer sdk.catchInterfacePanic(&xerr)
+	p := parseParams(params, 1, 3)
+	var (
+		sendTxArgs   SendTxArgs
+		contractArgs ContractExtension
+		passwd       string
+	)
+	p.decode(0, &sendTxArgs)
+	switch p.len() {
+	case 2:
+		p.decode(1, &contractArgs)
+	case 3:
+		passwd = p.string(1)
+		p.decode(2, &contractArgs)
 	}
-	var sendTxArgs SendTxArgs
-	err := sendTxArgs.parseFromArgs(args[0])
-	if err != nil {
-		return common.Hash{}, ErrSendTxArgs.Join(err)
+	if xerr := p.error(); xerr != nil {
+		return common.Hash{}, xerr
+	}
+	claim, dup, xerr := sdk.claimIdempotency(ctx, &sendTxArgs, &contractArgs)
+	if xerr != nil {
+		return common.Hash{}, xerr
+	}
+	if dup != nil {
+		return dup, ErrSuccess
+	}
+	if claim != nil {
+		defer sdk.finishIdempotency(claim)
+		ctx = context.WithValue(ctx, idempotencyClaimKey{}, claim)
 	}
 	if sdk.cfg.GetGasPrice {
-		sendTxArgs.GasPrice = sdk.gasPrice
+		sendTxArgs.GasPrice = sdk.gasPrice.get()
 	}
 	account := accounts.Account{Address: sendTxArgs.From}
 	wallet, err := sdk.am.Find(account)
@@ -251,72 +272,59 @@ func (sdk *SDKImpl) SendContractTransaction(params interface{}) (interface{}, *E
 		sdk.nonceLock.lockAddr(sendTxArgs.From)
 		defer sdk.nonceLock.unlockAddr(sendTxArgs.From)
 	}
-	if err = sendTxArgs.setDefaults(sdk.c); err != nil {
+	if err = sendTxArgs.setDefaults(ctx, sdk.c); err != nil {
 		return common.Hash{}, ErrSendTxArgs.Join(err)
 	}
-	tx := sendTxArgs.toContractTransaction()
+	tx := sendTxArgs.toContractTransaction(sdk.signHash)
 	stx, ok := tx.(accounts.SingerTx)
 	if !ok {
 		return nil, ErrSendTxArgs.Join(fmt.Errorf("tx is not a SignerTx type"))
 	}
 
-	var contractArgs ContractExtension
 	//send contract transaction without password
-	switch len(args) {
+	switch p.len() {
 	case 1:
-		signed, err := wallet.SignTx(account, stx, sdk.signParam)
-		debug.FreeOSMemory()
+		signed, err := sdk.signTx(ctx, wallet, account, stx)
 		if err != nil {
 			return common.Hash{}, ErrSDKSignTx.Join(err)
 		}
 		txbal, err := bal.EncodeToBytes(signed)
 		if err != nil {
-			sdklog.Error("SendContractTransaction bal.EncodeToBytes()", "err", err)
+			sdk.log.Error("SendContractTransaction bal.EncodeToBytes()", "err", err)
 			return common.Hash{}, ErrBalEncodeToBytes.Join(err)
 		}
-		res, xerr := sdk.c.sendTransaction(common.ToHex(txbal))
+		res, xerr := sdk.broadcast(ctx, signed, &sendTxArgs, common.ToHex(txbal), nil)
 		if xerr.Code != 0 {
 			res = common.Hash{}
 		}
 		return res, xerr
 	case 2:
-		err = contractArgs.parseFromArgs(args[1])
-		if err != nil {
-			return common.Hash{}, ErrContractExtension.Join(err)
-		}
-		signed, err := wallet.SignTx(account, stx, sdk.signParam)
-		debug.FreeOSMemory()
+		signed, err := sdk.signTx(ctx, wallet, account, stx)
 		if err != nil {
 			return common.Hash{}, ErrSDKSignTx.Join(err)
 		}
 		txbal, err := bal.EncodeToBytes(signed)
 		if err != nil {
-			sdklog.Error("SendContractTransaction bal.EncodeToBytes()", "err", err)
+			sdk.log.Error("SendContractTransaction bal.EncodeToBytes()", "err", err)
 			return common.Hash{}, ErrBalEncodeToBytes.Join(err)
 		}
-		res, xerr := sdk.c.sendContractTransaction(common.ToHex(txbal), contractArgs)
+		res, xerr := sdk.broadcast(ctx, signed, &sendTxArgs, common.ToHex(txbal), contractArgs)
 		if xerr.Code != 0 {
 			res = common.Hash{}
 		}
 		return res, xerr
 	case 3:
 		//send contract transaction with password
-		passwd := args[1].(string)
-		err = contractArgs.parseFromArgs(args[2])
-		if err != nil {
-			return common.Hash{}, ErrContractExtension.Join(err)
-		}
-		signed, err := wallet.SignTxWithPassphrase(account, passwd, stx, sdk.signParam)
-		debug.FreeOSMemory()
+		signed, err := sdk.signTxWithPassphrase(ctx, wallet, account, passwd, stx)
 		if err != nil {
 			return common.Hash{}, ErrSDKSignTxWithPassphrase.Join(err)
 		}
 		txbal, err := bal.EncodeToBytes(signed)
 		if err != nil {
-			sdklog.Error("SendContractTransaction bal.EncodeToBytes()", "err", err)
+			sdk.log.Error("SendContractTransaction bal.EncodeToBytes()", "err", err)
 			return common.Hash{}, ErrBalEncodeToBytes.Join(err)
 		}
-		res, xerr := sdk.c.sendContractTransaction(common.ToHex(txbal), contractArgs)
+		res, xerr := sdk.broadcast(ctx, signed, &sendTxArgs, common.ToHex(txbal), contractArgs)
 		if xerr.Code != 0 {
 			res = common.Hash{}
 		}
@@ -326,39 +334,37 @@ func (sdk *SDKImpl) SendContractTransaction(params interface{}) (interface{}, *E
 	}
 }
 
-func (sdk *SDKImpl) Call(params interface{}) (interface{}, *Error) {
-	defer catchInterfacePanic()
-	args := params.([]interface{})
-	if len(args) != 1 {
-		return nil, ErrParams
-	}
+func (sdk *SDKImpl) Call(params interface{}) (_ interface{}, xerr *Error) {
+	ctx, span := sdk.startSpan("Call")
+	defer span.finish(&xerr)
+	defer sdk.catchInterfacePanic(&xerr)
+	p := parseParams(params, 1, 1)
 	var callArgs CallArgs
-	err := callArgs.parseFromArgs(args[0])
-	if err != nil {
-		return nil, ErrSendTxArgs.Join(err)
+	p.decode(0, &callArgs)
+	if xerr := p.error(); xerr != nil {
+		return nil, xerr
 	}
 	account := accounts.Account{Address: common.HexToAddress(callArgs.From)}
-	_, err = sdk.am.Find(account)
+	_, err := sdk.am.Find(account)
 	if err != nil {
 		return nil, ErrAccountFind.Join(err)
 	}
-	return sdk.c.call(callArgs.From, callArgs.To, callArgs.Data)
+	return sdk.c.call(ctx, callArgs.From, callArgs.To, callArgs.Data)
 }
 
 // SignTx sign tx with unlocked account and returns raw
-func (sdk *SDKImpl) SignTx(params interface{}) (interface{}, *Error) {
-	defer catchInterfacePanic()
-	args := params.([]interface{})
-	if len(args) != 1 {
-		return "", ErrParams
-	}
+func (sdk *SDKImpl) SignTx(params interface{}) (_ interface{}, xerr *Error) {
+	ctx, span := sdk.startSpan("SignTx")
+	defer span.finish(&xerr)
+	defer sdk.catchInterfacePanic(&xerr)
+	p := parseParams(params, 1, 1)
 	var signTxArgs SendTxArgs
-	err := signTxArgs.parseFromArgs(args[0])
-	if err != nil {
-		return "", ErrSignTxArgs.Join(err)
+	p.decode(0, &signTxArgs)
+	if xerr := p.error(); xerr != nil {
+		return "", xerr
 	}
 	if sdk.cfg.GetGasPrice {
-		signTxArgs.GasPrice = sdk.gasPrice
+		signTxArgs.GasPrice = sdk.gasPrice.get()
 	}
 	account := accounts.Account{Address: signTxArgs.From}
 	wallet, err := sdk.am.Find(account)
@@ -368,15 +374,15 @@ func (sdk *SDKImpl) SignTx(params interface{}) (interface{}, *Error) {
 	if signTxArgs.Nonce == nil {
 		return "", ErrSignTxArgs.Join(fmt.Errorf("nonce should not be nil"))
 	}
-	if err = signTxArgs.setDefaults(sdk.c); err != nil {
+	if err = signTxArgs.setDefaults(ctx, sdk.c); err != nil {
 		return "", ErrSignTxArgs.Join(err)
 	}
-	tx := signTxArgs.toTransaction()
+	tx := signTxArgs.toTransaction(sdk.signHash)
 	stx, ok := tx.(accounts.SingerTx)
 	if !ok {
 		return nil, ErrSignTxArgs.Join(fmt.Errorf("tx is not a SignerTx type"))
 	}
-	signed, err := wallet.SignTx(account, stx, sdk.signParam)
+	signed, err := sdk.signTx(ctx, wallet, account, stx)
 	if err != nil {
 		return "", ErrSignTxArgs.Join(err)
 	}
@@ -388,14 +394,304 @@ func (sdk *SDKImpl) SignTx(params interface{}) (interface{}, *Error) {
 }
 
 // SendRawTransaction send raw and returns hash
-func (sdk *SDKImpl) SendRawTransaction(params interface{}) (interface{}, *Error) {
-	args := params.([]interface{})
-	if len(args) != 1 {
-		return "", ErrParams
+func (sdk *SDKImpl) SendRawTransaction(params interface{}) (_ interface{}, xerr *Error) {
+	ctx, span := sdk.startSpan("SendRawTransaction")
+	defer span.finish(&xerr)
+	p := parseParams(params, 1, 1)
+	raw := p.string(0)
+	p.hex(0) // raw must be hex encoded
+	if xerr := p.error(); xerr != nil {
+		return "", xerr
+	}
+	return sdk.c.sendTransaction(ctx, raw)
+}
+
+// NewMnemonic generates a mnemonic, imports it as the HD wallet seed and returns it.
+// The seed is kept in memory only, the caller persists the mnemonic. A loaded seed
+// is replaced only if overwrite is set.
+func (sdk *SDKImpl) NewMnemonic(params interface{}) (_ interface{}, xerr *Error) {
+	_, span := sdk.startSpan("NewMnemonic")
+	defer span.finish(&xerr)
+	defer sdk.catchInterfacePanic(&xerr)
+	p := parseParams(params, 0, 2)
+	bits := 128
+	if p.len() >= 1 {
+		bits = int(p.uint(0, 32))
+	}
+	overwrite := p.bool(1)
+	if xerr := p.error(); xerr != nil {
+		return "", xerr
+	}
+	mnemonic, err := NewMnemonic(bits)
+	if err != nil {
+		return "", ErrHDWallet.Join(err)
 	}
-	raw, ok := args[0].(string)
-	if !ok {
-		return "", ErrSendRawTransaction.Join(fmt.Errorf("params[0] type error"))
+	if err = sdk.hd.setMnemonic(mnemonic, "", overwrite); err != nil {
+		return "", ErrHDWallet.Join(err)
+	}
+	return mnemonic, nil
+}
+
+// ImportMnemonic imports a mnemonic (with an optional BIP-39 passphrase) as the HD wallet
+// seed. A loaded seed is replaced only if overwrite is set.
+func (sdk *SDKImpl) ImportMnemonic(params interface{}) (_ interface{}, xerr *Error) {
+	_, span := sdk.startSpan("ImportMnemonic")
+	defer span.finish(&xerr)
+	defer sdk.catchInterfacePanic(&xerr)
+	p := parseParams(params, 1, 3)
+	mnemonic := p.string(0)
+	passphrase := p.string(1)
+	overwrite := p.bool(2)
+	if xerr := p.error(); xerr != nil {
+		return false, xerr
+	}
+	if err := sdk.hd.setMnemonic(mnemonic, passphrase, overwrite); err != nil {
+		return false, ErrHDWallet.Join(err)
+	}
+	return true, nil
+}
+
+// DeriveAddress returns the address at the given index without storing its key
+func (sdk *SDKImpl) DeriveAddress(params interface{}) (_ interface{}, xerr *Error) {
+	_, span := sdk.startSpan("DeriveAddress")
+	defer span.finish(&xerr)
+	defer sdk.catchInterfacePanic(&xerr)
+	p := parseParams(params, 1, 1)
+	index := uint32(p.uint(0, 32))
+	if xerr := p.error(); xerr != nil {
+		return common.Address{}, xerr
+	}
+	addr, err := sdk.hd.deriveAddress(index)
+	if err != nil {
+		return common.Address{}, ErrHDWallet.Join(err)
+	}
+	return addr, nil
+}
+
+// DeriveAccount stores the key at the given index into keystore and unlocks it
+func (sdk *SDKImpl) DeriveAccount(params interface{}) (_ interface{}, xerr *Error) {
+	_, span := sdk.startSpan("DeriveAccount")
+	defer span.finish(&xerr)
+	defer sdk.catchInterfacePanic(&xerr)
+	p := parseParams(params, 2, 2)
+	index := uint32(p.uint(0, 32))
+	passwd := p.string(1)
+	if xerr := p.error(); xerr != nil {
+		return common.Address{}, xerr
+	}
+	key, err := sdk.hd.derive(index)
+	if err != nil {
+		return common.Address{}, ErrHDWallet.Join(err)
+	}
+	defer key.Reset()
+	ks := sdk.keyStore()
+	acc, err := ks.ImportECDSA(key, passwd)
+	if err != nil {
+		sdk.log.Error("derive account fail", "index", index, "err", err)
+		return common.Address{}, ErrHDWallet.Join(err)
+	}
+	err = sdk.unlocks.unlock(ks, acc, passwd, sdk.cfg.UnlockTimeout)
+	if err != nil {
+		sdk.log.Error("derive account unlock fail", "account", acc)
+	}
+	return acc.Address, nil
+}
+
+// ImportRawKey stores a hex encoded private key into keystore encrypted with passwd
+func (sdk *SDKImpl) ImportRawKey(params interface{}) (_ interface{}, xerr *Error) {
+	_, span := sdk.startSpan("ImportRawKey")
+	defer span.finish(&xerr)
+	defer sdk.catchInterfacePanic(&xerr)
+	p := parseParams(params, 2, 2)
+	raw := p.hex(0)
+	passwd := p.string(1)
+	if xerr := p.error(); xerr != nil {
+		return common.Address{}, xerr
+	}
+	key, err := crypto.GeneratePrivKeyFromSecret(raw, crypto.LocalAccountType())
+	if err != nil {
+		return common.Address{}, ErrImportAccount.Join(err)
+	}
+	defer key.Reset()
+	ks := sdk.keyStore()
+	acc, err := ks.ImportECDSA(key, passwd)
+	if err != nil {
+		sdk.log.Error("import raw key fail", "err", err)
+		return common.Address{}, ErrImportAccount.Join(err)
+	}
+	err = sdk.unlocks.unlock(ks, acc, passwd, sdk.cfg.UnlockTimeout)
+	if err != nil {
+		sdk.log.Error("import raw key unlock fail", "account", acc.Address)
+	}
+	return acc.Address, nil
+}
+
+// ImportKeystore stores a keystore json into keystore, re-encrypted with newPasswd if given
+func (sdk *SDKImpl) ImportKeystore(params interface{}) (_ interface{}, xerr *Error) {
+	_, span := sdk.startSpan("ImportKeystore")
+	defer span.finish(&xerr)
+	defer sdk.catchInterfacePanic(&xerr)
+	p := parseParams(params, 2, 3)
+	var keyJSON []byte
+	switch v := p.raw(0).(type) {
+	case string:
+		keyJSON = []byte(v)
+	case map[string]interface{}:
+		keyJSON, _ = json.Marshal(v)
+	default:
+		p.err = newParamError("params[0]", "expected string or object, got %s", jsonType(v))
+	}
+	passwd := p.string(1)
+	newPasswd := passwd
+	if p.len() == 3 {
+		newPasswd = p.string(2)
+	}
+	if xerr := p.error(); xerr != nil {
+		return common.Address{}, xerr
+	}
+	var header struct {
+		Address string `json:"address"`
+	}
+	if err := json.Unmarshal(keyJSON, &header); err != nil {
+		return common.Address{}, ErrJsonUnmarshal.Join(err)
+	}
+	ks := sdk.keyStore()
+	if ks.HasAddress(common.HexToAddress(header.Address)) {
+		return common.Address{}, ErrImportAccount.Join(fmt.Errorf("account already exists"))
+	}
+	acc, err := ks.Import(keyJSON, passwd, newPasswd)
+	if err != nil {
+		sdk.log.Error("import keystore fail", "err", err)
+		return common.Address{}, ErrImportAccount.Join(err)
+	}
+	err = sdk.unlocks.unlock(ks, acc, newPasswd, sdk.cfg.UnlockTimeout)
+	if err != nil {
+		sdk.log.Error("import keystore unlock fail", "account", acc.Address)
+	}
+	return acc.Address, nil
+}
+
+// ExportKeystore returns the keystore json of addr, re-encrypted with newPasswd if given
+func (sdk *SDKImpl) ExportKeystore(params interface{}) (_ interface{}, xerr *Error) {
+	_, span := sdk.startSpan("ExportKeystore")
+	defer span.finish(&xerr)
+	defer sdk.catchInterfacePanic(&xerr)
+	p := parseParams(params, 2, 3)
+	account := accounts.Account{Address: p.address(0)}
+	passwd := p.string(1)
+	newPasswd := passwd
+	if p.len() == 3 {
+		newPasswd = p.string(2)
+	}
+	if xerr := p.error(); xerr != nil {
+		return nil, xerr
+	}
+	keyJSON, err := sdk.keyStore().Export(account, passwd, newPasswd)
+	debug.FreeOSMemory()
+	if err != nil {
+		return nil, ErrExportAccount.Join(err)
+	}
+	return json.RawMessage(keyJSON), nil
+}
+
+// UpdatePassword changes the password of addr's key file
+func (sdk *SDKImpl) UpdatePassword(params interface{}) (_ interface{}, xerr *Error) {
+	_, span := sdk.startSpan("UpdatePassword")
+	defer span.finish(&xerr)
+	defer sdk.catchInterfacePanic(&xerr)
+	p := parseParams(params, 3, 3)
+	account := accounts.Account{Address: p.address(0)}
+	passwd := p.string(1)
+	newPasswd := p.string(2)
+	if xerr := p.error(); xerr != nil {
+		return false, xerr
+	}
+	err := sdk.keyStore().Update(account, passwd, newPasswd)
+	debug.FreeOSMemory()
+	if err != nil {
+		return false, ErrUpdatePassword.Join(err)
+	}
+	return true, nil
+}
+
+// DeleteAccount removes addr's key file and drops its unlocked key
+func (sdk *SDKImpl) DeleteAccount(params interface{}) (_ interface{}, xerr *Error) {
+	_, span := sdk.startSpan("DeleteAccount")
+	defer span.finish(&xerr)
+	defer sdk.catchInterfacePanic(&xerr)
+	p := parseParams(params, 2, 2)
+	account := accounts.Account{Address: p.address(0)}
+	passwd := p.string(1)
+	if xerr := p.error(); xerr != nil {
+		return false, xerr
+	}
+	ks := sdk.keyStore()
+	err := ks.Delete(account, passwd)
+	debug.FreeOSMemory()
+	if err != nil {
+		return false, ErrDeleteAccount.Join(err)
+	}
+	sdk.unlocks.lock(ks, account.Address)
+	return true, nil
+}
+
+// UnlockAccount unlocks addr for duration seconds, 0 means indefinitely
+func (sdk *SDKImpl) UnlockAccount(params interface{}) (_ interface{}, xerr *Error) {
+	_, span := sdk.startSpan("UnlockAccount")
+	defer span.finish(&xerr)
+	defer sdk.catchInterfacePanic(&xerr)
+	p := parseParams(params, 2, 3)
+	addr := p.string(0)
+	account := accounts.Account{Address: p.address(0)}
+	passwd := p.string(1)
+	duration := p.uint(2, 64)
+	if xerr := p.error(); xerr != nil {
+		return false, xerr
+	}
+	// a larger duration overflows to a negative one, which unlocks indefinitely
+	if duration > uint64(maxUnlockDuration/time.Second) {
+		return false, ErrParams.Join(newParamError("params[2]", "duration above %d seconds, use 0 to unlock indefinitely", uint64(maxUnlockDuration/time.Second)))
+	}
+	if _, err := sdk.am.Find(account); err != nil {
+		return false, ErrAccountFind.Join(err)
+	}
+	err := sdk.unlocks.unlock(sdk.keyStore(), account, passwd, time.Duration(duration)*time.Second)
+	if err != nil {
+		sdk.log.Error("unlock account fail", "account", addr, "err", err)
+		return false, ErrUnlockAccount.Join(err)
+	}
+	return true, nil
+}
+
+// LockAccount drops the unlocked key of addr from memory
+func (sdk *SDKImpl) LockAccount(params interface{}) (_ interface{}, xerr *Error) {
+	_, span := sdk.startSpan("LockAccount")
+	defer span.finish(&xerr)
+	defer sdk.catchInterfacePanic(&xerr)
+	p := parseParams(params, 1, 1)
+	account := accounts.Account{Address: p.address(0)}
+	if xerr := p.error(); xerr != nil {
+		return false, xerr
+	}
+	if _, err := sdk.am.Find(account); err != nil {
+		return false, ErrAccountFind.Join(err)
+	}
+	sdk.unlocks.lock(sdk.keyStore(), account.Address)
+	return true, nil
+}
+
+// AccountStatus returns whether addr is unlocked and when it relocks
+func (sdk *SDKImpl) AccountStatus(params interface{}) (_ interface{}, xerr *Error) {
+	_, span := sdk.startSpan("AccountStatus")
+	defer span.finish(&xerr)
+	defer sdk.catchInterfacePanic(&xerr)
+	p := parseParams(params, 1, 1)
+	account := accounts.Account{Address: p.address(0)}
+	if xerr := p.error(); xerr != nil {
+		return nil, xerr
+	}
+	if _, err := sdk.am.Find(account); err != nil {
+		return nil, ErrAccountFind.Join(err)
 	}
-	return sdk.c.sendTransaction(raw)
+	return sdk.unlocks.state(sdk.keyStore(), account.Address), nil
 }
@@ -0,0 +1,470 @@
+package sdk
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/XunleiBlockchain/tc-libs/common"
+	"github.com/XunleiBlockchain/tc-libs/common/hexutil"
+)
+
+// Subscription kinds of SDKImpl.Subscribe
+const (
+	SubNewHeads        = "newHeads"        // 新区块
+	SubPendingTxStatus = "pendingTxStatus" // 本SDK发送的交易的上链状态
+	SubLogs            = "logs"            // 合约日志
+)
+
+// Status of a TxStatus notification
+const (
+	TxPending = "pending" // 已发送 尚未上链
+	TxSuccess = "success" // 已上链 执行成功
+	TxFailed  = "failed"  // 已上链 执行失败
+	TxDropped = "dropped" // 超时未上链
+)
+
+const (
+	defaultPollInterval = 2 * time.Second
+	subscriptionBuffer  = 256              // 订阅未读通知上限 超过时结束订阅
+	pendingTxTimeout    = 30 * time.Minute // 超时未上链的交易通知dropped
+	maxPollBlocks       = 100              // 每次轮询至多通知的区块数 落后更多时跳过
+)
+
+var (
+	errSubscriptionLagged = errors.New("too many unread notifications")
+	errSubscriptionClosed = errors.New("sdk closed")
+)
+
+// TxStatus is the notification of a pendingTxStatus subscription
+type TxStatus struct {
+	Hash        common.Hash `json:"hash"`
+	Status      string      `json:"status"`
+	BlockNumber string      `json:"blockNumber,omitempty"`
+	Receipt     interface{} `json:"receipt,omitempty"`
+}
+
+// SubscribeFilter is the optional filter object of Subscribe
+type SubscribeFilter struct {
+	Addresses []common.Address // logs: 合约地址 为空不限制
+	Topics    [][]common.Hash  // logs: 按位置匹配的topic 位置为空不限制 多个为或
+	Hashes    []common.Hash    // pendingTxStatus: 交易哈希 为空时为本SDK发送的全部交易
+}
+
+func (f *SubscribeFilter) parseFromArgs(x interface{}, field string) (err error) {
+	obj, err := toObject(x, field)
+	if err != nil {
+		return err
+	}
+	if addr := obj["address"]; addr != nil {
+		list, ok := addr.([]interface{})
+		if !ok {
+			list = []interface{}{addr}
+		}
+		for i, a := range list {
+			address, err := toAddress(a, indexField(field+".address", i, ok))
+			if err != nil {
+				return err
+			}
+			f.Addresses = append(f.Addresses, address)
+		}
+	}
+	if topics := obj["topics"]; topics != nil {
+		list, ok := topics.([]interface{})
+		if !ok {
+			return newParamError(field+".topics", "expected array, got %s", jsonType(topics))
+		}
+		for i, t := range list {
+			var hashes []common.Hash
+			alts, isList := t.([]interface{})
+			if !isList && t != nil {
+				alts = []interface{}{t}
+			}
+			for j, alt := range alts {
+				h, err := toHash(alt, indexField(indexField(field+".topics", i, true), j, isList))
+				if err != nil {
+					return err
+				}
+				hashes = append(hashes, common.HexToHash(h))
+			}
+			f.Topics = append(f.Topics, hashes)
+		}
+	}
+	if hashes := obj["hashes"]; hashes != nil {
+		list, ok := hashes.([]interface{})
+		if !ok {
+			return newParamError(field+".hashes", "expected array, got %s", jsonType(hashes))
+		}
+		for i, x := range list {
+			h, err := toHash(x, indexField(field+".hashes", i, true))
+			if err != nil {
+				return err
+			}
+			f.Hashes = append(f.Hashes, common.HexToHash(h))
+		}
+	}
+	return nil
+}
+
+func indexField(field string, i int, indexed bool) string {
+	if !indexed {
+		return field
+	}
+	return fmt.Sprintf("%s[%d]", field, i)
+}
+
+// matchLog reports whether the log object of a receipt passes the address and topics filter
+func (f *SubscribeFilter) matchLog(log map[string]interface{}) bool {
+	if len(f.Addresses) != 0 {
+		addr, _ := log["address"].(string)
+		found := false
+		for _, a := range f.Addresses {
+			found = found || strings.EqualFold(a.Hex(), addr)
+		}
+		if !found {
+			return false
+		}
+	}
+	topics, _ := log["topics"].([]interface{})
+	if len(f.Topics) > len(topics) {
+		return false
+	}
+	for i, alts := range f.Topics {
+		if len(alts) == 0 {
+			continue
+		}
+		topic, _ := topics[i].(string)
+		found := false
+		for _, h := range alts {
+			found = found || strings.EqualFold(h.Hex(), topic)
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *SubscribeFilter) matchTx(hash common.Hash) bool {
+	if len(f.Hashes) == 0 {
+		return true
+	}
+	for _, h := range f.Hashes {
+		if h == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscription delivers the notifications of a Subscribe until Unsubscribe or
+// Close: blocks for newHeads, TxStatus for pendingTxStatus and receipt log objects
+// for logs. A subscriber not keeping up is ended with Err set.
+type Subscription struct {
+	ID     string
+	Kind   string
+	filter SubscribeFilter
+	ch     chan interface{}
+	err    error
+	w      *watcher
+}
+
+// C returns the notification channel, it is closed when the subscription ends
+func (s *Subscription) C() <-chan interface{} {
+	return s.ch
+}
+
+// Err returns why the subscription ended, nil while active or after Unsubscribe
+func (s *Subscription) Err() error {
+	s.w.mu.Lock()
+	defer s.w.mu.Unlock()
+	return s.err
+}
+
+// Unsubscribe ends the subscription
+func (s *Subscription) Unsubscribe() {
+	s.w.unsubscribe(s.ID)
+}
+
+// Subscribe starts a subscription backed by polling BaaS every Config.PollInterval.
+// Params are the kind newHeads, pendingTxStatus or logs and an optional filter
+// object: {"hashes":[...]} for pendingTxStatus, {"address":..,"topics":[...]} for logs.
+func (sdk *SDKImpl) Subscribe(params interface{}) (_ *Subscription, xerr *Error) {
+	_, span := sdk.startSpan("Subscribe")
+	defer span.finish(&xerr)
+	defer sdk.catchInterfacePanic(&xerr)
+	p := parseParams(params, 1, 2)
+	kind := p.string(0)
+	var filter SubscribeFilter
+	p.decode(1, &filter)
+	if xerr := p.error(); xerr != nil {
+		return nil, xerr
+	}
+	switch {
+	case kind != SubNewHeads && kind != SubPendingTxStatus && kind != SubLogs:
+		return nil, ErrParams.Join(newParamError("params[0]", "unknown subscription %s", kind))
+	case kind == SubNewHeads && p.len() == 2:
+		return nil, ErrParams.Join(newParamError("params[1]", "newHeads takes no filter"))
+	case kind != SubLogs && (len(filter.Addresses) != 0 || len(filter.Topics) != 0):
+		return nil, ErrParams.Join(newParamError("params[1]", "address and topics only filter logs"))
+	case kind != SubPendingTxStatus && len(filter.Hashes) != 0:
+		return nil, ErrParams.Join(newParamError("params[1]", "hashes only filter pendingTxStatus"))
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, ErrSubscribe.Join(err)
+	}
+	sub := &Subscription{
+		ID:     "0x" + hex.EncodeToString(id),
+		Kind:   kind,
+		filter: filter,
+		ch:     make(chan interface{}, subscriptionBuffer),
+		w:      sdk.watcher,
+	}
+	if err := sdk.watcher.subscribe(sub); err != nil {
+		return nil, ErrSubscribe.Join(err)
+	}
+	return sub, nil
+}
+
+// watcher polls BaaS for the subscriptions of a SDK, it runs while there are any
+type watcher struct {
+	c        *client
+	log      Logger
+	interval time.Duration
+
+	mu      sync.Mutex
+	subs    map[string]*Subscription
+	pending map[common.Hash]time.Time // 待上链交易及发送时间
+	running bool
+	closed  bool
+}
+
+func newWatcher(c *client, interval time.Duration, log Logger) *watcher {
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	return &watcher{
+		c:        c,
+		log:      log,
+		interval: interval,
+		subs:     make(map[string]*Subscription),
+		pending:  make(map[common.Hash]time.Time),
+	}
+}
+
+func (w *watcher) subscribe(sub *Subscription) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return errSubscriptionClosed
+	}
+	w.subs[sub.ID] = sub
+	now := time.Now()
+	for _, h := range sub.filter.Hashes {
+		if _, ok := w.pending[h]; !ok {
+			w.pending[h] = now
+		}
+	}
+	if !w.running {
+		w.running = true
+		go w.loop()
+	}
+	return nil
+}
+
+func (w *watcher) unsubscribe(id string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if sub, ok := w.subs[id]; ok {
+		w.end(sub, nil)
+	}
+}
+
+// end closes sub with err, w.mu must be held
+func (w *watcher) end(sub *Subscription, err error) {
+	delete(w.subs, sub.ID)
+	sub.err = err
+	close(sub.ch)
+}
+
+// close ends all subscriptions and refuses new ones
+func (w *watcher) close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	for _, sub := range w.subs {
+		w.end(sub, errSubscriptionClosed)
+	}
+}
+
+// track records a transaction accepted by BaaS for the pendingTxStatus subscriptions
+func (w *watcher) track(hash common.Hash) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.has(SubPendingTxStatus) {
+		return
+	}
+	w.pending[hash] = time.Now()
+	w.notify(SubPendingTxStatus, &TxStatus{Hash: hash, Status: TxPending}, func(sub *Subscription) bool {
+		return sub.filter.matchTx(hash)
+	})
+}
+
+// has reports whether there is a subscription of kind, w.mu must be held
+func (w *watcher) has(kind string) bool {
+	for _, sub := range w.subs {
+		if sub.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// notify sends v to the subscriptions of kind matching it, w.mu must be held
+func (w *watcher) notify(kind string, v interface{}, match func(*Subscription) bool) {
+	for _, sub := range w.subs {
+		if sub.Kind != kind || (match != nil && !match(sub)) {
+			continue
+		}
+		select {
+		case sub.ch <- v:
+		default:
+			w.log.Warn("subscription lagged", "id", sub.ID, "kind", sub.Kind)
+			w.end(sub, errSubscriptionLagged)
+		}
+	}
+}
+
+func (w *watcher) loop() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	var next uint64 // 下一个待通知的区块 0表示尚未开始
+	for range ticker.C {
+		if !w.poll(&next) {
+			return
+		}
+	}
+}
+
+// poll runs one round of polling, false when there is no subscription left
+func (w *watcher) poll(next *uint64) bool {
+	w.mu.Lock()
+	if len(w.subs) == 0 || w.closed {
+		w.running = false
+		w.pending = make(map[common.Hash]time.Time)
+		w.mu.Unlock()
+		return false
+	}
+	heads, logs, txs := w.has(SubNewHeads), w.has(SubLogs), w.has(SubPendingTxStatus)
+	pending := make(map[common.Hash]time.Time, len(w.pending))
+	if txs {
+		for h, t := range w.pending {
+			pending[h] = t
+		}
+	} else {
+		w.pending = make(map[common.Hash]time.Time)
+	}
+	w.mu.Unlock()
+
+	ctx := context.Background()
+	if heads || logs {
+		w.pollBlocks(ctx, next, logs)
+	} else {
+		*next = 0
+	}
+	for hash, sent := range pending {
+		w.pollTx(ctx, hash, sent)
+	}
+	return true
+}
+
+// pollBlocks notifies the blocks from next on, the first poll starts after the current block
+func (w *watcher) pollBlocks(ctx context.Context, next *uint64, logs bool) {
+	number, xerr := w.c.getBlockNumber(ctx)
+	if xerr.Code != 0 {
+		w.log.Warn("subscription poll blockNumber fail", "err", xerr)
+		return
+	}
+	if *next == 0 || number+1 < *next {
+		*next = number + 1
+		return
+	}
+	if number+1-*next > maxPollBlocks {
+		w.log.Warn("subscription skips blocks", "from", *next, "to", number-maxPollBlocks)
+		*next = number + 1 - maxPollBlocks
+	}
+	for ; *next <= number; *next++ {
+		block, xerr := w.c.getBlockByNumber(ctx, hexutil.EncodeUint64(*next), false)
+		if xerr.Code != 0 || block == nil {
+			w.log.Warn("subscription poll block fail", "number", *next, "err", xerr)
+			return
+		}
+		w.mu.Lock()
+		w.notify(SubNewHeads, block, nil)
+		w.mu.Unlock()
+		if logs {
+			w.pollLogs(ctx, block)
+		}
+	}
+}
+
+// pollLogs notifies the logs of the block's transactions
+func (w *watcher) pollLogs(ctx context.Context, block interface{}) {
+	b, _ := block.(map[string]interface{})
+	txs, _ := b["transactions"].([]interface{})
+	for _, tx := range txs {
+		hash, _ := tx.(string)
+		receipt, xerr := w.c.getTransactionReceipt(ctx, hash)
+		if xerr.Code != 0 {
+			w.log.Warn("subscription poll receipt fail", "hash", hash, "err", xerr)
+			continue
+		}
+		r, _ := receipt.(map[string]interface{})
+		list, _ := r["logs"].([]interface{})
+		w.mu.Lock()
+		for _, l := range list {
+			log, ok := l.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			w.notify(SubLogs, log, func(sub *Subscription) bool {
+				return sub.filter.matchLog(log)
+			})
+		}
+		w.mu.Unlock()
+	}
+}
+
+// pollTx notifies the status of a pending transaction once it is mined or dropped
+func (w *watcher) pollTx(ctx context.Context, hash common.Hash, sent time.Time) {
+	receipt, xerr := w.c.getTransactionReceipt(ctx, hash.Hex())
+	if xerr.Code != 0 {
+		w.log.Warn("subscription poll receipt fail", "hash", hash, "err", xerr)
+		return
+	}
+	var status *TxStatus
+	if r, ok := receipt.(map[string]interface{}); ok {
+		status = &TxStatus{Hash: hash, Status: TxFailed, Receipt: receipt}
+		status.BlockNumber, _ = r["blockNumber"].(string)
+		if s, _ := r["status"].(string); s == "0x1" {
+			status.Status = TxSuccess
+		}
+	} else if time.Since(sent) > pendingTxTimeout {
+		status = &TxStatus{Hash: hash, Status: TxDropped}
+	}
+	if status == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.pending, hash)
+	w.notify(SubPendingTxStatus, status, func(sub *Subscription) bool {
+		return sub.filter.matchTx(hash)
+	})
+}
@@ -0,0 +1,176 @@
+package sdk
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// W3C trace context headers, see https://www.w3.org/TR/trace-context/
+const (
+	TraceParentHeader = "traceparent"
+	TraceStateHeader  = "tracestate"
+)
+
+// SpanContext identifies a span across process boundaries
+type SpanContext struct {
+	TraceID    [16]byte
+	SpanID     [8]byte
+	Flags      byte   // 0x01 为 sampled
+	TraceState string // 透传的 tracestate 头
+}
+
+// IsValid reports whether both trace id and span id are non-zero
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != [16]byte{} && sc.SpanID != [8]byte{}
+}
+
+// TraceParent formats sc as a W3C traceparent header value
+func (sc SpanContext) TraceParent() string {
+	return fmt.Sprintf("00-%x-%x-%02x", sc.TraceID[:], sc.SpanID[:], sc.Flags)
+}
+
+// ParseTraceParent parses a W3C traceparent header value
+func ParseTraceParent(s string) (SpanContext, error) {
+	var sc SpanContext
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return sc, fmt.Errorf("invalid traceparent: %q", s)
+	}
+	// future versions may append fields, version 00 must have exactly 4
+	if parts[0] == "00" && len(parts) != 4 {
+		return sc, fmt.Errorf("invalid traceparent: %q", s)
+	}
+	if len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return sc, fmt.Errorf("invalid traceparent: %q", s)
+	}
+	// the spec allows lower case hex only
+	for _, p := range parts[:4] {
+		if !isLowerHex(p) {
+			return sc, fmt.Errorf("invalid traceparent: %q", s)
+		}
+	}
+	var flags [1]byte
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return sc, fmt.Errorf("invalid traceparent trace id: %v", err)
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return sc, fmt.Errorf("invalid traceparent span id: %v", err)
+	}
+	if _, err := hex.Decode(flags[:], []byte(parts[3])); err != nil {
+		return sc, fmt.Errorf("invalid traceparent flags: %v", err)
+	}
+	sc.Flags = flags[0]
+	if !sc.IsValid() {
+		return sc, fmt.Errorf("invalid traceparent: %q", s)
+	}
+	return sc, nil
+}
+
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+type spanContextKey struct{}
+
+// ContextWithSpanContext returns a copy of ctx carrying sc as the current span
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// SpanContextFromContext returns the current span carried by ctx
+func SpanContextFromContext(ctx context.Context) (SpanContext, bool) {
+	sc, ok := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc, ok && sc.IsValid()
+}
+
+// ExtractTraceContext returns a copy of ctx carrying the span in the traceparent
+// and tracestate headers of h, ctx is returned unchanged if there is none.
+func ExtractTraceContext(ctx context.Context, h http.Header) context.Context {
+	sc, err := ParseTraceParent(h.Get(TraceParentHeader))
+	if err != nil {
+		return ctx
+	}
+	sc.TraceState = h.Get(TraceStateHeader)
+	return ContextWithSpanContext(ctx, sc)
+}
+
+// InjectTraceContext sets the traceparent and tracestate headers of the current span in ctx
+func InjectTraceContext(ctx context.Context, h http.Header) {
+	sc, ok := SpanContextFromContext(ctx)
+	if !ok {
+		return
+	}
+	h.Set(TraceParentHeader, sc.TraceParent())
+	if sc.TraceState != "" {
+		h.Set(TraceStateHeader, sc.TraceState)
+	}
+}
+
+// Span is a traced operation started by Tracer
+type Span interface {
+	// SpanContext returns the context propagated to BaaS for this span
+	SpanContext() SpanContext
+	SetAttributes(kv ...interface{})
+	RecordError(err error)
+	End()
+}
+
+// Tracer is the tracing hook, Start begins a child span of the span in ctx.
+// Adapters for OpenTelemetry and the like convert their span context to SpanContext.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// nopTracer keeps the incoming span, so trace context is still propagated to BaaS
+type nopTracer struct{}
+
+func (nopTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	sc, _ := SpanContextFromContext(ctx)
+	return ctx, nopSpan{sc: sc}
+}
+
+type nopSpan struct {
+	sc SpanContext
+}
+
+func (s nopSpan) SpanContext() SpanContext   { return s.sc }
+func (nopSpan) SetAttributes(...interface{}) {}
+func (nopSpan) RecordError(error)            {}
+func (nopSpan) End()                         {}
+
+// startSpan starts a span with tracer and makes it the current span of the returned ctx
+func startSpan(tracer Tracer, ctx context.Context, name string) (context.Context, *span) {
+	ctx, s := tracer.Start(ctx, name)
+	if sc := s.SpanContext(); sc.IsValid() {
+		ctx = ContextWithSpanContext(ctx, sc)
+	}
+	return ctx, &span{s}
+}
+
+type span struct {
+	Span
+}
+
+// end records err if any and ends the span
+func (s *span) end(err error) {
+	if err != nil {
+		s.RecordError(err)
+	}
+	s.End()
+}
+
+// finish ends the span of an SDK call with its result, to be deferred with a named result
+func (s *span) finish(xerr **Error) {
+	if *xerr != nil && (*xerr).Code != 0 {
+		s.RecordError(*xerr)
+	}
+	s.End()
+}
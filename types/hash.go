@@ -0,0 +1,36 @@
+package types
+
+import (
+	"fmt"
+
+	"github.com/XunleiBlockchain/tc-libs/bal"
+	"github.com/XunleiBlockchain/tc-libs/common"
+	"github.com/XunleiBlockchain/tc-libs/types"
+	"github.com/tjfoc/gmsm/sm3"
+)
+
+// Sign hash types
+const (
+	HashKeccak256 = "keccak256"
+	HashSM3       = "sm3"
+)
+
+// CheckSignHashType returns an error if t is not a sign hash type.
+// An empty type means keccak256.
+func CheckSignHashType(t string) error {
+	switch t {
+	case "", HashKeccak256, HashSM3:
+		return nil
+	}
+	return fmt.Errorf("invalid sign hash type: %s", t)
+}
+
+// sigHash returns the hash of x's bal encoding using the sign hash type t.
+func sigHash(t string, x interface{}) (h common.Hash) {
+	if t != HashSM3 {
+		return types.BalHash(x)
+	}
+	enc, _ := bal.EncodeToBytes(x)
+	copy(h[:], sm3.Sm3Sum(enc))
+	return h
+}
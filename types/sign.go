@@ -34,10 +34,10 @@ type signdata struct {
 	signFieldsFunc func() []interface{}
 }
 
-// Sign signs the signdata using the given signer and private key
-func sign(signer types.STDSigner, prv crypto.PrivKey, data []interface{}) (*big.Int, *big.Int, *big.Int, error) {
+// Sign signs the signdata using the given signer, sign hash type and private key
+func sign(signer types.STDSigner, hashType string, prv crypto.PrivKey, data []interface{}) (*big.Int, *big.Int, *big.Int, error) {
 	fields := append(data, signer.SignParam(), uint(0), uint(0))
-	h := types.BalHash(fields)
+	h := sigHash(hashType, fields)
 
 	sig, err := prv.Sign(h[:])
 	if err != nil {
@@ -47,10 +47,10 @@ func sign(signer types.STDSigner, prv crypto.PrivKey, data []interface{}) (*big.
 	return signer.SignatureValues(sig.Raw())
 }
 
-// VerifySign The signature should be in [R || S || V] format.
-func VerifySign(signer types.STDSigner, prv []byte, data []interface{}, sign []byte) bool {
+// VerifySign The signature should be in [R || S || V] format, hashType is the sign hash type.
+func VerifySign(signer types.STDSigner, hashType string, prv []byte, data []interface{}, sign []byte) bool {
 	fields := append(data, signer.SignParam(), uint(0), uint(0))
-	h := types.BalHash(fields)
+	h := sigHash(hashType, fields)
 	return crypto.VerifySignature(prv, h[:], sign)
 }
 
@@ -61,7 +61,7 @@ func VerifySign(signer types.STDSigner, prv []byte, data []interface{}, sign []b
 // Sender may cache the address, allowing it to be used regardless of
 // signing method. The cache is invalidated if the cached signer does
 // not match the signer used in the current call.
-func sender(signer types.STDSigner, data types.SignerData) (common.Address, error) {
+func sender(signer types.STDSigner, hashType string, data types.SignerData) (common.Address, error) {
 	if sc := data.From().Load(); sc != nil {
 		sigCache := sc.(stdSigCache)
 		// If the signer used to derive from in a previous
@@ -72,7 +72,7 @@ func sender(signer types.STDSigner, data types.SignerData) (common.Address, erro
 		}
 	}
 
-	addr, err := signer.Sender(data)
+	addr, err := recoverSender(signer, hashType, data)
 	if err != nil {
 		return common.EmptyAddress, err
 	}
@@ -80,11 +80,30 @@ func sender(signer types.STDSigner, data types.SignerData) (common.Address, erro
 	return addr, nil
 }
 
-func senders(signer types.STDSigner, signatures []*signdata, signFields func() []interface{}) ([]common.Address, error) {
+// recoverSender derives the sender with the sign hash type hashType. The signer's
+// own Sender always uses keccak256, so it is only used for that hash type.
+func recoverSender(signer types.STDSigner, hashType string, data types.SignerData) (common.Address, error) {
+	if hashType != HashSM3 {
+		return signer.Sender(data)
+	}
+	if signer.SignParam() == nil || !data.Protected() {
+		return data.Recover(sigHash(hashType, data.SignFields()), nil, true)
+	}
+	// SM2 signatures carry V offset by crypto.SM2Magic, which shifts the derived param by 4.
+	signParam := data.SignParam()
+	if signParam.Cmp(signer.SignParam()) != 0 && signParam.Sub(signParam, big.NewInt(4)).Cmp(signer.SignParam()) != 0 {
+		return common.EmptyAddress, types.ErrInvalidSignParam
+	}
+	fields := append(data.SignFields(), signer.SignParam(), uint(0), uint(0))
+	signParamMul := new(big.Int).Mul(signer.SignParam(), big.NewInt(2))
+	return data.Recover(sigHash(hashType, fields), signParamMul, true)
+}
+
+func senders(signer types.STDSigner, hashType string, signatures []*signdata, signFields func() []interface{}) ([]common.Address, error) {
 	addrs := make([]common.Address, 0, len(signatures))
 	for _, s := range signatures {
 		s.setSignFieldsFunc(signFields)
-		addr, err := sender(signer, s)
+		addr, err := sender(signer, hashType, s)
 		if err != nil {
 			return nil, err
 		}
@@ -34,7 +34,8 @@ var _ Tx = (*Transaction)(nil)
 var _ types.SignerData = (*txdata)(nil)
 
 type Transaction struct {
-	data txdata
+	data     txdata
+	signHash string // 签名哈希算法 为空即keccak256 不参与编码
 	// caches
 	hash       atomic.Value
 	size       atomic.Value
@@ -98,12 +99,23 @@ func (data txdata) SignParam() *big.Int {
 	return deriveSignParam(data.V)
 }
 
+// SetSignHashType sets the hash algorithm tx is signed and its sender recovered
+// with, see CheckSignHashType. The default is keccak256.
+func (tx *Transaction) SetSignHashType(t string) {
+	tx.signHash = t
+}
+
+// SignHashType returns the hash algorithm tx is signed with, "" for keccak256
+func (tx *Transaction) SignHashType() string {
+	return tx.signHash
+}
+
 func (tx *Transaction) Sign(signer types.STDSigner, prv crypto.PrivKey) error {
-	r, s, v, err := sign(signer, prv, tx.data.SignFields())
+	r, s, v, err := sign(signer, tx.signHash, prv, tx.data.SignFields())
 	if err != nil {
 		return err
 	}
-	cpy := &Transaction{data: tx.data}
+	cpy := &Transaction{data: tx.data, signHash: tx.signHash}
 	cpy.data.R, cpy.data.S, cpy.data.V = r, s, v
 	*tx = *cpy
 	return nil
@@ -125,7 +137,7 @@ func (tx *Transaction) VirifySign(signer types.STDSigner, prv []byte) bool {
 		V = byte(tx.data.V.Uint64() - 27)
 	}
 	sign[64] = V
-	return VerifySign(signer, prv, tx.data.SignFields(), sign)
+	return VerifySign(signer, tx.signHash, prv, tx.data.SignFields(), sign)
 }
 func (tx *Transaction) balHahs() {
 	types.BalHash(tx.data)
@@ -137,11 +149,12 @@ func (tx *Transaction) AddTtl() {
 	tx.data.ttl++
 }
 func (tx *Transaction) SignHash() common.Hash {
-	return GlobalSTDSigner.Hash(&tx.data)
+	fields := append(tx.data.SignFields(), GlobalSTDSigner.SignParam(), uint(0), uint(0))
+	return sigHash(tx.signHash, fields)
 }
 
 func (tx *Transaction) Sender(signer types.STDSigner) (common.Address, error) {
-	return sender(signer, &tx.data)
+	return sender(signer, tx.signHash, &tx.data)
 }
 
 // SignParam returns which sign param this transaction was signed with
@@ -256,7 +269,7 @@ func (tx *Transaction) IllegalGasLimitOrGasPrice() bool {
 	}
 
 	if !IsContract(tx.Data()) && tx.Gas() != ParGasLimit {
-		fmt.Printf("!IsContract(tx.Data()) && tx.Gas() != ParGasLimit:  !IsContract(tx.Data()) = %+v\n", !IsContract(tx.Data()), "tx.Gas()", tx.Gas(), "ParGasLimit", ParGasLimit)
+		fmt.Printf("!IsContract(tx.Data()) && tx.Gas() != ParGasLimit:  !IsContract(tx.Data()) = %+v tx.Gas() = %+v ParGasLimit = %+v\n", !IsContract(tx.Data()), tx.Gas(), ParGasLimit)
 		return true
 	}
 	fmt.Printf("false!\n")
@@ -319,7 +332,7 @@ func (tx *Transaction) UnmarshalJSON(input []byte) error {
 	if !crypto.ValidateSignatureValues(V, dec.R, dec.S, false) {
 		return ErrInvalidSig
 	}
-	*tx = Transaction{data: dec}
+	*tx = Transaction{data: dec, signHash: tx.signHash}
 	return nil
 }
 
@@ -403,7 +416,7 @@ func (tx *Transaction) WithSignature(signer types.STDSigner, sig []byte) (*Trans
 	if err != nil {
 		return nil, err
 	}
-	cpy := &Transaction{data: tx.data}
+	cpy := &Transaction{data: tx.data, signHash: tx.signHash}
 	cpy.data.R, cpy.data.S, cpy.data.V = r, s, v
 	return cpy, nil
 }
@@ -1,10 +1,13 @@
 package sdk
 
 import (
+	crand "crypto/rand"
+	"encoding/hex"
+	"fmt"
 	"math/rand"
 	"net"
+	"runtime/debug"
 	"strings"
-	"time"
 )
 
 func getRand() int {
@@ -19,17 +22,33 @@ func getRand() int {
 const alphabeta string = "0123456789abcdefghijklmnopqrstuvwxyz"
 
 func getRandString(n int) string {
-	rand.Seed(time.Now().UnixNano())
 	buf := make([]byte, n)
-	rand.Read(buf[:])
-	rc := byte(0)
+	if _, err := crand.Read(buf); err != nil {
+		panic(err)
+	}
 	for k, v := range buf {
-		rc = v & 0x23
-		buf[k] = alphabeta[rc]
+		// 252 is the largest multiple of 36 below 256, keeps the alphabet uniform
+		for v >= 252 {
+			var b [1]byte
+			if _, err := crand.Read(b[:]); err != nil {
+				panic(err)
+			}
+			v = b[0]
+		}
+		buf[k] = alphabeta[int(v)%len(alphabeta)]
 	}
 	return string(buf)
 }
 
+// getRandHex returns n random bytes in hex
+func getRandHex(n int) string {
+	buf := make([]byte, n)
+	if _, err := crand.Read(buf); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(buf)
+}
+
 // is isIPV4 or not
 func isIPV4(add string) bool {
 	ip := net.ParseIP(add)
@@ -40,16 +59,10 @@ func strAND(s string) string {
 	return "&" + s
 }
 
-func catchInterfacePanic() {
+// catchInterfacePanic recovers a panic of a SDK method and reports it as ErrInternal
+func (sdk *SDKImpl) catchInterfacePanic(xerr **Error) {
 	if r := recover(); r != nil {
-		sdklog.Error("catchInterfacePanic: %v", r)
-	}
-}
-
-func checkStrRes(x interface{}, defaultRes string) string {
-	res, ok := x.(string)
-	if ok {
-		return res
+		sdk.log.Error("catchInterfacePanic", "panic", r, "stack", string(debug.Stack()))
+		*xerr = ErrInternal.Join(fmt.Errorf("%v", r))
 	}
-	return defaultRes
 }